@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/gophoria/gophoria/pkg/diagnostic"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +30,7 @@ func init() {
 	cwd, _ := os.Getwd()
 
 	rootCmd.PersistentFlags().StringVarP(&cfg.file, "file", "f", "project.gophoria", "Gophoria main file")
-	rootCmd.PersistentFlags().StringVarP(&cfg.workingDir, "workingDir", "w", cwd, "Gophoria main file")
+	rootCmd.PersistentFlags().StringVar(&cfg.workingDir, "workingDir", cwd, "Directory the code and migrations are generated in")
 }
 
 func Execute() {
@@ -39,6 +41,21 @@ func Execute() {
 }
 
 func exitWithError(err error) {
+	var diags diagnostic.Diagnostics
+	if errors.As(err, &diags) {
+		diags.Render(os.Stderr, readSource)
+		os.Exit(1)
+	}
+
 	fmt.Fprintf(os.Stderr, "%s\n", err)
 	os.Exit(1)
 }
+
+func readSource(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gophoria/gophoria/pkg/parser"
+	"github.com/gophoria/gophoria/pkg/printer"
+	"github.com/spf13/cobra"
+)
+
+type FmtConfig struct {
+	write bool
+	check bool
+}
+
+var fmtCfg FmtConfig
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [files...]",
+	Short: "Format gophoria files",
+	Long: `Fmt prints the files, the main file by default, in the canonical format.
+A directory stands for every gophoria file in it, imports are not followed.
+With -w the formatted files are written back instead.`,
+	Run: func(_ *cobra.Command, args []string) {
+		err := format(args)
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().BoolVarP(&fmtCfg.write, "write", "w", false, "Write the formatted file instead of printing it")
+	fmtCmd.Flags().BoolVar(&fmtCfg.check, "check", false, "List the files which are not formatted and fail if there are any")
+}
+
+func format(files []string) error {
+	if len(files) == 0 {
+		files = []string{cfg.file}
+	}
+
+	files, err := expandDirs(files)
+	if err != nil {
+		return err
+	}
+
+	unformatted := 0
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		ast, err := parser.ParseFile(file, source)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		err = printer.NewPrinter(&buf).Print(ast)
+		if err != nil {
+			return err
+		}
+
+		formatted := bytes.Equal(source, buf.Bytes())
+
+		switch {
+		case fmtCfg.check:
+			if !formatted {
+				fmt.Println(file)
+				unformatted++
+			}
+		case fmtCfg.write:
+			if !formatted {
+				err = os.WriteFile(file, buf.Bytes(), 0644)
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			os.Stdout.Write(buf.Bytes())
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("%d of %d files are not formatted", unformatted, len(files))
+	}
+
+	return nil
+}
+
+// expandDirs replaces the directories among the files with the gophoria
+// files they contain.
+func expandDirs(files []string) ([]string, error) {
+	expanded := []string{}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			expanded = append(expanded, file)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(file, "*"+parser.Extension))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+
+		expanded = append(expanded, matches...)
+	}
+
+	return expanded, nil
+}
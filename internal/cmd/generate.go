@@ -3,15 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os/exec"
+	"path"
+	"time"
 
 	"github.com/gophoria/gophoria/internal/utils"
+	"github.com/gophoria/gophoria/pkg/analyzer"
 	"github.com/gophoria/gophoria/pkg/ast"
 	"github.com/gophoria/gophoria/pkg/generator"
+	"github.com/gophoria/gophoria/pkg/migration"
 	"github.com/spf13/cobra"
 )
 
 type GenerateConfig struct {
 	override bool
+	name     string
 }
 
 var generateCfg GenerateConfig
@@ -49,12 +54,13 @@ func init() {
 	rootCmd.AddCommand(generateCmd)
 
 	generateCmd.PersistentFlags().BoolVar(&generateCfg.override, "override", false, "Override files if exists")
+	generateDbCommand.Flags().StringVar(&generateCfg.name, "name", "", "Name of the migration, defaults to init or update")
 	generateCmd.AddCommand(generateDbCommand)
 	generateCmd.AddCommand(generateUiCommand)
 }
 
 func generateDb() error {
-	ast, err := utils.ParseFile(cfg.file)
+	ast, err := utils.LoadProject(cfg.file)
 	if err != nil {
 		return err
 	}
@@ -87,6 +93,9 @@ func generateDb() error {
 	return nil
 }
 
+// generateMigrations writes a migration from the schema of the last run,
+// stored in migrations/snapshot.json, to the current schema. Generators
+// without incremental migrations recreate a file per table.
 func generateMigrations(ast *ast.Ast) error {
 	gen, err := createMigrationGenerator(ast)
 	if err != nil {
@@ -95,8 +104,54 @@ func generateMigrations(ast *ast.Ast) error {
 
 	cfg := createGeneratorCfg()
 
+	migrationGen, ok := gen.(generator.MigrationGenerator)
+	if !ok {
+		return generateTables(gen, ast, cfg)
+	}
+
+	snapshotFile := path.Join(cfg.WorkingDir, migration.SnapshotFile)
+
+	prev, err := migration.LoadSnapshot(snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	m := migration.Diff(prev, ast)
+	if m.IsEmpty() {
+		fmt.Println("No schema changes, no migration generated")
+		return nil
+	}
+
+	name := generateCfg.name
+	if name == "" {
+		name = "update"
+		if len(prev.Models) == 0 && len(prev.Enums) == 0 {
+			name = "init"
+		}
+	}
+
+	name = time.Now().UTC().Format("20060102150405") + "_" + name
+
+	err = migrationGen.GenerateMigration(m, cfg, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Generated migration %s\n", name)
+
+	return migration.SaveSnapshot(snapshotFile, ast)
+}
+
+func generateTables(gen generator.Generator, ast *ast.Ast, cfg *generator.GeneratorConfig) error {
 	for _, item := range ast.Models {
-		err = gen.Generate(ast, cfg, item.Name.Identifier)
+		err := gen.Generate(ast, cfg, item.Name.Identifier)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range analyzer.JoinTables(ast) {
+		err := gen.Generate(ast, cfg, table.Name)
 		if err != nil {
 			return err
 		}
@@ -154,7 +209,7 @@ func generateModels(ast *ast.Ast) error {
 		}
 	}
 
-	return nil
+	return gen.Generate(ast, cfg, "Queries")
 }
 
 func createMigrationGenerator(ast *ast.Ast) (generator.Generator, error) {
@@ -196,7 +251,7 @@ func createLibraryGenerator(ast *ast.Ast) (generator.Generator, error) {
 }
 
 func generateUi() error {
-	ast, err := utils.ParseFile(cfg.file)
+	ast, err := utils.LoadProject(cfg.file)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gophoria/gophoria/pkg/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run the language server over stdio",
+	Run: func(_ *cobra.Command, _ []string) {
+		err := lsp.NewServer(os.Stdin, os.Stdout).Run()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
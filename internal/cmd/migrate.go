@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"text/tabwriter"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/gophoria/gophoria/internal/utils"
+	"github.com/gophoria/gophoria/pkg/migration"
+	"github.com/spf13/cobra"
+)
+
+var sqlDrivers = map[string]string{
+	"sqlite3":  "sqlite3",
+	"postgres": "pgx",
+	"mysql":    "mysql",
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply migrations to the database",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+var migrateUpCommand = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations",
+	Run: func(_ *cobra.Command, _ []string) {
+		err := migrateUp()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var migrateDownCommand = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the last applied migration",
+	Run: func(_ *cobra.Command, _ []string) {
+		err := migrateDown()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var migrateStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	Run: func(_ *cobra.Command, _ []string) {
+		err := migrateStatus()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var migrateRedoCommand = &cobra.Command{
+	Use:   "redo",
+	Short: "Revert and apply the last applied migration again",
+	Run: func(_ *cobra.Command, _ []string) {
+		err := migrateRedo()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.AddCommand(migrateUpCommand)
+	migrateCmd.AddCommand(migrateDownCommand)
+	migrateCmd.AddCommand(migrateStatusCommand)
+	migrateCmd.AddCommand(migrateRedoCommand)
+}
+
+func migrateUp() error {
+	migrator, db, err := createMigrator()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	names, err := migrator.Up()
+	for _, name := range names {
+		fmt.Printf("Applied %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No pending migrations")
+	}
+
+	return nil
+}
+
+func migrateDown() error {
+	migrator, db, err := createMigrator()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	name, err := migrator.Down()
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Println("No applied migrations")
+		return nil
+	}
+
+	fmt.Printf("Reverted %s\n", name)
+
+	return nil
+}
+
+func migrateRedo() error {
+	migrator, db, err := createMigrator()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	name, err := migrator.Redo()
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Println("No applied migrations")
+		return nil
+	}
+
+	fmt.Printf("Redone %s\n", name)
+
+	return nil
+}
+
+func migrateStatus() error {
+	migrator, db, err := createMigrator()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "missing"
+		case status.Modified:
+			state = "modified"
+		case status.Applied:
+			state = "applied"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", state, status.Name, status.AppliedAt)
+	}
+
+	return w.Flush()
+}
+
+// createMigrator connects to the database of the db config block. Mysql
+// runs a migration file as a single statement only if multiStatements is
+// set, it is added to the url.
+func createMigrator() (*migration.Migrator, *sql.DB, error) {
+	ast, err := utils.LoadProject(cfg.file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, ok := ast.ConfigItem("db", "provider")
+	if !ok {
+		return nil, nil, fmt.Errorf("unable to find db provider")
+	}
+
+	url, ok := ast.ConfigItem("db", "url")
+	if !ok {
+		return nil, nil, fmt.Errorf("unable to find db url")
+	}
+
+	driver, ok := sqlDrivers[provider.Value.Value]
+	if !ok {
+		return nil, nil, fmt.Errorf("migrations are not supported for provider %s", provider.Value.Value)
+	}
+
+	dsn := url.Value.Value
+	if driver == "mysql" {
+		mysqlCfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mysqlCfg.MultiStatements = true
+		dsn = mysqlCfg.FormatDSN()
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrator, err := migration.NewMigrator(db, provider.Value.Value, path.Join(cfg.workingDir, "migrations"))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return migrator, db, nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/gophoria/gophoria/internal/utils"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/introspect"
+	"github.com/gophoria/gophoria/pkg/printer"
+	"github.com/spf13/cobra"
+)
+
+type PullConfig struct {
+	write bool
+}
+
+var pullCfg PullConfig
+
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Read enums and models from the database",
+	Long: `Pull reads the tables of the database of the db config block and prints
+them as enums and models with the config of the main file. The main file is
+only replaced with --write, its enums and models are lost.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		err := pull()
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+
+	pullCmd.Flags().BoolVarP(&pullCfg.write, "write", "w", false, "Replace the main file instead of printing the schema")
+}
+
+func pull() error {
+	ast, err := utils.ParseFile(cfg.file)
+	if err != nil {
+		return err
+	}
+
+	provider, ok := ast.ConfigItem("db", "provider")
+	if !ok {
+		return fmt.Errorf("unable to find db provider")
+	}
+
+	url, ok := ast.ConfigItem("db", "url")
+	if !ok {
+		return fmt.Errorf("unable to find db url")
+	}
+
+	introspector, err := introspect.GetIntrospector(provider.Value.Value)
+	if err != nil {
+		return err
+	}
+
+	driver, ok := sqlDrivers[provider.Value.Value]
+	if !ok {
+		return fmt.Errorf("unable to find driver for provider %s", provider.Value.Value)
+	}
+
+	db, err := sql.Open(driver, url.Value.Value)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	schema, err := introspector.Introspect(db, decorator.NamingOf(ast))
+	if err != nil {
+		return err
+	}
+	schema.Config = ast.Config
+
+	if !pullCfg.write {
+		return printer.NewPrinter(os.Stdout).Print(schema)
+	}
+
+	var buf bytes.Buffer
+	err = printer.NewPrinter(&buf).Print(schema)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(cfg.file, buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pulled %d enums and %d models into %s\n", len(schema.Enums), len(schema.Models), cfg.file)
+
+	return nil
+}
@@ -5,6 +5,7 @@ var DateTime = []byte(`package db
 
 import (
   "database/sql/driver"
+  "encoding/json"
   "fmt"
   "time"
 )
@@ -15,6 +16,32 @@ func Now() DateTime {
  return DateTime(time.Now())
 }
 
+func (d DateTime) Time() time.Time {
+	return time.Time(d)
+}
+
+// MarshalJSON keeps the fractional seconds, which Value cuts.
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(d).Format(time.RFC3339Nano))
+}
+
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	var source string
+	err := json.Unmarshal(data, &source)
+	if err != nil {
+		return err
+	}
+
+	dateTime, err := time.Parse(time.RFC3339Nano, source)
+	if err != nil {
+		return err
+	}
+
+	*d = DateTime(dateTime)
+
+	return nil
+}
+
 func (d DateTime) Value() (driver.Value, error) {
 	t := time.Time(d)
 	return t.Format(time.RFC3339), nil
@@ -23,6 +50,9 @@ func (d DateTime) Value() (driver.Value, error) {
 func (d *DateTime) Scan(src interface{}) error {
 	var source string
 	switch src := src.(type) {
+		case time.Time:
+			*d = DateTime(src)
+			return nil
 		case string:
 			source = src
 		case []byte:
@@ -33,11 +63,40 @@ func (d *DateTime) Scan(src interface{}) error {
 
 	dateTime, err := time.Parse(time.RFC3339, source)
 	if err != nil {
-		return err
+		// CURRENT_TIMESTAMP of sqlite, which is in UTC
+		dateTime, err = time.Parse(time.DateTime, source)
+		if err != nil {
+			return err
+		}
 	}
 
 	*d = DateTime(dateTime)
 
 	return nil
 }
+
+// NullDateTime is a DateTime which may be NULL.
+type NullDateTime struct {
+	DateTime DateTime
+	Valid    bool
+}
+
+func (n NullDateTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.DateTime.Value()
+}
+
+func (n *NullDateTime) Scan(src interface{}) error {
+	if src == nil {
+		n.DateTime, n.Valid = DateTime{}, false
+		return nil
+	}
+
+	n.Valid = true
+
+	return n.DateTime.Scan(src)
+}
 `)
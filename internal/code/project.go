@@ -19,6 +19,6 @@ model Post {
   content   string  @nullable
   public    bool    @default(false)
   author    User    @relation(field: authorId, reference: id)
-  authorId  int
+  authorId  string
 }
 `)
@@ -1,21 +1,32 @@
 package utils
 
 import (
-	"os"
-
+	"github.com/gophoria/gophoria/pkg/analyzer"
 	"github.com/gophoria/gophoria/pkg/ast"
-	"github.com/gophoria/gophoria/pkg/lexer"
 	"github.com/gophoria/gophoria/pkg/parser"
 )
 
+// ParseFile parses the file, or every gophoria file of the directory, and
+// the files they import.
 func ParseFile(fileName string) (*ast.Ast, error) {
-	data, err := os.ReadFile(fileName)
+	return parser.NewLoader().Load(fileName)
+}
+
+// LoadProject parses the file and checks that the schema is valid, so it
+// can be passed to generators.
+func LoadProject(fileName string) (*ast.Ast, error) {
+	ast, err := ParseFile(fileName)
 	if err != nil {
 		return nil, err
 	}
 
-	lexer := lexer.NewLexer(string(data))
-	parser := parser.NewParser(lexer)
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err != nil {
+		analyzer.Diagnostics().SetFile(fileName)
+		return nil, err
+	}
 
-	return parser.Parse()
+	return ast, nil
 }
@@ -3,12 +3,38 @@ package utils
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 func Capitalize(str string) string {
 	return fmt.Sprintf("%c%s", str[0]-32, str[1:])
 }
 
+func Uncapitalize(str string) string {
+	if str == "" {
+		return str
+	}
+
+	return strings.ToLower(str[:1]) + str[1:]
+}
+
+// Singular returns the singular of an english plural, names which do not
+// look like a plural are returned as they are.
+func Singular(str string) string {
+	switch {
+	case strings.HasSuffix(str, "ies") && len(str) > 3:
+		return str[:len(str)-3] + "y"
+	case strings.HasSuffix(str, "ses") || strings.HasSuffix(str, "xes"):
+		return str[:len(str)-2]
+	case strings.HasSuffix(str, "ss"):
+		return str
+	case strings.HasSuffix(str, "s") && len(str) > 1:
+		return str[:len(str)-1]
+	}
+
+	return str
+}
+
 func WriteString(writer io.Writer, str string) (int, error) {
 	return writer.Write([]byte(str))
 }
@@ -0,0 +1,485 @@
+package analyzer
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+)
+
+const (
+	CodeUnknownType        = "A001"
+	CodeDuplicateSymbol    = "A002"
+	CodeDuplicateField     = "A003"
+	CodeMissingId          = "A004"
+	CodeMultipleId         = "A005"
+	CodeUnknownField       = "A006"
+	CodeEmptyEnum          = "A007"
+	CodeMixedEnumValues    = "A008"
+	CodeDuplicateEnumValue = "A009"
+	CodeNotNullable        = "A010"
+	CodeAmbiguousRelation  = "A011"
+	CodeUnmatchedRelation  = "A012"
+	CodeInvalidEnumValue   = "A013"
+	CodeInvalidConfig      = "A014"
+	CodeTypeMismatch       = "A015"
+)
+
+// Analyzer checks that an ast is semantically valid, i.e. every type
+// reference resolves, names are unique and decorators point at existing
+// fields.
+type Analyzer struct {
+	ast     *ast.Ast
+	symbols *SymbolTable
+
+	diagnostics diagnostic.Diagnostics
+}
+
+func NewAnalyzer(ast *ast.Ast) *Analyzer {
+	a := Analyzer{
+		ast:     ast,
+		symbols: NewSymbolTable(),
+	}
+
+	return &a
+}
+
+// Analyze builds the symbol table of the ast and validates it. Like the
+// parser it reports every problem it finds, the returned error is a
+// diagnostic.Diagnostics if there is at least one error.
+func (a *Analyzer) Analyze() (*SymbolTable, error) {
+	a.collectSymbols()
+	a.analyzeConfig()
+
+	for _, enum := range a.ast.Enums {
+		a.analyzeEnum(enum)
+	}
+
+	for _, model := range a.ast.Models {
+		a.analyzeModel(model)
+	}
+
+	a.analyzeTableNames()
+	a.analyzeManyToMany()
+
+	return a.symbols, a.diagnostics.Err()
+}
+
+func (a *Analyzer) Diagnostics() diagnostic.Diagnostics {
+	return a.diagnostics
+}
+
+func (a *Analyzer) report(diag *diagnostic.Diagnostic) {
+	a.diagnostics = append(a.diagnostics, diag)
+}
+
+func (a *Analyzer) collectSymbols() {
+	for _, enum := range a.ast.Enums {
+		a.addSymbol(newEnumSymbol(enum))
+	}
+
+	for _, model := range a.ast.Models {
+		symbol := newModelSymbol(model)
+		if !a.addSymbol(symbol) {
+			continue
+		}
+
+		for _, item := range model.Items {
+			existing, ok := symbol.fields[item.Identifier.Identifier]
+			if ok {
+				diag := diagnostic.Errorf(CodeDuplicateField, item.Identifier.Token, "field %s is already declared in model %s", item.Identifier.Identifier, symbol.Name)
+				a.report(diag.WithHint("previous declaration is at " + diagnostic.Location(existing.Identifier.Token)))
+				continue
+			}
+
+			symbol.fields[item.Identifier.Identifier] = item
+		}
+	}
+}
+
+func (a *Analyzer) addSymbol(symbol *Symbol) bool {
+	existing, ok := a.symbols.Add(symbol)
+	if ok {
+		return true
+	}
+
+	diag := diagnostic.Errorf(CodeDuplicateSymbol, symbol.Token(), "%s is already declared", symbol.Name)
+	a.report(diag.WithHint("previous declaration is at " + diagnostic.Location(existing.Token())))
+
+	return false
+}
+
+// Nullables are the values of the nullable option of the db block, which
+// picks the Go types of nullable fields in the sqlx generator.
+var Nullables = []string{"sql", "generic", "pointer"}
+
+// analyzeConfig checks the options of the db block which have a fixed set
+// of values, i.e. naming, nullable and context.
+func (a *Analyzer) analyzeConfig() {
+	if item, ok := a.ast.ConfigItem("db", "naming"); ok {
+		if _, ok := decorator.LookupNaming(item.Value.Value); !ok || item.Value.Type != ast.ValueTypeString {
+			diag := diagnostic.Errorf(CodeInvalidConfig, item.Value.Token, "unknown naming %s", item.Value)
+			a.report(diag.WithHint("naming is one of " + strings.Join(decorator.Namings, ", ")))
+		}
+	}
+
+	if item, ok := a.ast.ConfigItem("db", "nullable"); ok {
+		if !slices.Contains(Nullables, item.Value.Value) || item.Value.Type != ast.ValueTypeString {
+			diag := diagnostic.Errorf(CodeInvalidConfig, item.Value.Token, "unknown nullable %s", item.Value)
+			a.report(diag.WithHint("nullable is one of " + strings.Join(Nullables, ", ")))
+		}
+	}
+
+	if item, ok := a.ast.ConfigItem("db", "context"); ok && item.Value.Type != ast.ValueTypeBool {
+		diag := diagnostic.Errorf(CodeInvalidConfig, item.Value.Token, "context is %s but should be a bool", item.Value)
+		a.report(diag.WithHint("context is true or false"))
+	}
+}
+
+func (a *Analyzer) analyzeEnum(enum *ast.Enum) {
+	if len(enum.Items) == 0 {
+		a.report(diagnostic.Errorf(CodeEmptyEnum, enum.Name.Token, "enum %s has no items", enum.Name.Identifier))
+		return
+	}
+
+	valueType := enum.Items[0].Value.Type
+	names := map[string]*ast.AssignItem{}
+	values := map[string]*ast.AssignItem{}
+
+	for _, item := range enum.Items {
+		if _, ok := names[item.Identifier.Identifier]; ok {
+			a.report(diagnostic.Errorf(CodeDuplicateField, item.Identifier.Token, "item %s is already declared in enum %s", item.Identifier.Identifier, enum.Name.Identifier))
+		}
+		names[item.Identifier.Identifier] = item
+
+		if item.Value.Type != ast.ValueTypeString && item.Value.Type != ast.ValueTypeInt {
+			diag := diagnostic.Errorf(CodeInvalidEnumValue, item.Value.Token, "value of %s is not a string or an integer", item.Identifier.Identifier)
+			a.report(diag.WithHint("all items of an enum must be either strings or integers"))
+			continue
+		}
+
+		if item.Value.Type != valueType {
+			diag := diagnostic.Errorf(CodeMixedEnumValues, item.Value.Token, "value of %s has a different type than the first item of enum %s", item.Identifier.Identifier, enum.Name.Identifier)
+			a.report(diag.WithHint("all items of an enum must be either strings or integers"))
+		}
+
+		if existing, ok := values[item.Value.Value]; ok {
+			a.report(diagnostic.Errorf(CodeDuplicateEnumValue, item.Value.Token, "value %s is already used by %s", item.Value, existing.Identifier.Identifier))
+		}
+		values[item.Value.Value] = item
+	}
+}
+
+func (a *Analyzer) analyzeModel(model *ast.Model) {
+	var id *ast.Decorator
+
+	for _, item := range model.Items {
+		if !a.analyzeType(item) {
+			continue
+		}
+
+		a.analyzeDecorators(item)
+		decorators := decorator.ForField(item)
+
+		if decorators.Default != nil && decorators.Default.Value != nil && decorators.Default.Value.Type == ast.ValueTypeNull && decorators.Nullable == nil {
+			diag := diagnostic.Errorf(CodeNotNullable, decorators.Default.Value.Token, "null can not be the default of field %s", item.Identifier.Identifier)
+			a.report(diag.WithHint("add @nullable to " + item.Identifier.Identifier))
+		}
+
+		if decorators.Id != nil {
+			if id != nil {
+				diag := diagnostic.Errorf(CodeMultipleId, decorators.Id.Decorator.Name.Token, "model %s has more than one @id", model.Name.Identifier)
+				a.report(diag.WithHint("previous @id is at " + diagnostic.Location(id.Name.Token)))
+			} else {
+				id = decorators.Id.Decorator
+			}
+		}
+
+		if decorators.Relation != nil {
+			a.analyzeRelation(model, item, decorators.Relation)
+		}
+	}
+
+	if compositeId := decorator.ForModel(model).Id; compositeId != nil {
+		if id != nil {
+			diag := diagnostic.Errorf(CodeMultipleId, compositeId.Decorator.Name.Token, "model %s has both @id and @@id", model.Name.Identifier)
+			a.report(diag.WithHint("previous @id is at " + diagnostic.Location(id.Name.Token)))
+		}
+		id = compositeId.Decorator
+	}
+
+	if id == nil {
+		diag := diagnostic.Errorf(CodeMissingId, model.Name.Token, "model %s has no @id field", model.Name.Identifier)
+		a.report(diag.WithHint("mark the primary key field with @id or list the fields of a composite key with @@id"))
+	}
+
+	a.analyzeModelDecorators(model)
+	a.analyzeColumnNames(model)
+}
+
+// analyzeModelDecorators checks the @@ decorators of the model and that the
+// fields they list are columns of the model.
+func (a *Analyzer) analyzeModelDecorators(model *ast.Model) {
+	seen := map[string]*ast.Decorator{}
+
+	for _, dec := range model.Decorators {
+		spec, ok := decorator.LookupModel(dec.Name.Identifier)
+		if existing, found := seen[dec.Name.Identifier]; found && ok && !spec.Repeatable {
+			diag := diagnostic.Errorf(decorator.CodeDuplicateDecorator, dec.Name.Token, "@@%s is already applied to %s", dec.Name.Identifier, model.Name.Identifier)
+			a.report(diag.WithHint("previous @@" + dec.Name.Identifier + " is at " + diagnostic.Location(existing.Name.Token)))
+			continue
+		}
+		seen[dec.Name.Identifier] = dec
+
+		a.diagnostics = append(a.diagnostics, decorator.Check(dec, decorator.TargetModelDeclaration)...)
+	}
+
+	owner, ok := a.symbols.Lookup(model.Name.Identifier)
+	if !ok || owner.Model != model {
+		return
+	}
+
+	decorators := decorator.ForModel(model)
+	fieldLists := []*ast.Decorator{}
+	if decorators.Id != nil {
+		fieldLists = append(fieldLists, decorators.Id.Decorator)
+	}
+	for _, unique := range decorators.Unique {
+		fieldLists = append(fieldLists, unique.Decorator)
+	}
+	for _, index := range decorators.Index {
+		fieldLists = append(fieldLists, index.Decorator)
+	}
+	if decorators.Cursor != nil {
+		fieldLists = append(fieldLists, decorators.Cursor.Decorator)
+		a.analyzeCursor(owner, decorators.Cursor)
+	}
+
+	for _, dec := range fieldLists {
+		args, _ := decorator.Bind(dec)
+
+		for _, item := range args["fields"].Value.Items {
+			a.analyzeColumn(owner, item)
+		}
+	}
+}
+
+// analyzeCursor reports nullable fields of a cursor, their NULL rows would be
+// on no page.
+func (a *Analyzer) analyzeCursor(owner *Symbol, cursor *decorator.Cursor) {
+	args, _ := decorator.Bind(cursor.Decorator)
+
+	for _, item := range args["fields"].Value.Items {
+		field, ok := owner.Field(item.Value)
+		if ok && decorator.ForField(field).Nullable != nil {
+			diag := diagnostic.Errorf(CodeNotNullable, item.Token, "cursor field %s can not be @nullable", item.Value)
+			a.report(diag.WithHint("list fields which are not @nullable in @@cursor"))
+		}
+	}
+}
+
+// analyzeColumnNames reports fields of a model mapped to the same column.
+func (a *Analyzer) analyzeColumnNames(model *ast.Model) {
+	owner, ok := a.symbols.Lookup(model.Name.Identifier)
+	if !ok || owner.Model != model {
+		return
+	}
+
+	columns := map[string]*ast.Declaration{}
+
+	for _, item := range model.Items {
+		// duplicate fields are reported already
+		if field, _ := owner.Field(item.Identifier.Identifier); field != item {
+			continue
+		}
+
+		if symbol, ok := a.symbols.Resolve(item.DeclarationType); ok && symbol.Kind == SymbolKindModel {
+			continue
+		}
+
+		column := decorator.ColumnName(a.ast, item)
+		if existing, ok := columns[column]; ok {
+			diag := diagnostic.Errorf(CodeDuplicateField, item.Identifier.Token, "field %s has the same column %s as %s", item.Identifier.Identifier, column, existing.Identifier.Identifier)
+			a.report(diag.WithHint("map one of the fields to another column with @map(\"...\")"))
+			continue
+		}
+		columns[column] = item
+	}
+}
+
+// analyzeTableNames reports models mapped to the same table.
+func (a *Analyzer) analyzeTableNames() {
+	tables := map[string]*ast.Model{}
+
+	for _, model := range a.ast.Models {
+		// duplicate models are reported already
+		if owner, ok := a.symbols.Lookup(model.Name.Identifier); !ok || owner.Model != model {
+			continue
+		}
+
+		table := decorator.TableName(a.ast, model)
+		if existing, ok := tables[table]; ok {
+			diag := diagnostic.Errorf(CodeDuplicateSymbol, model.Name.Token, "model %s has the same table %s as %s", model.Name.Identifier, table, existing.Name.Identifier)
+			a.report(diag.WithHint("map one of the models to another table with @@map(\"...\")"))
+			continue
+		}
+		tables[table] = model
+	}
+}
+
+// analyzeColumn reports a field name which is not a column of the model.
+func (a *Analyzer) analyzeColumn(owner *Symbol, name *ast.Value) {
+	field, ok := owner.Field(name.Value)
+	if !ok {
+		a.report(diagnostic.Errorf(CodeUnknownField, name.Token, "model %s has no field %s", owner.Name, name.Value))
+		return
+	}
+
+	if symbol, ok := a.symbols.Resolve(field.DeclarationType); ok && symbol.Kind == SymbolKindModel {
+		diag := diagnostic.Errorf(CodeUnknownField, name.Token, "%s is a relation and not a column of model %s", name.Value, owner.Name)
+		a.report(diag.WithHint("use the field holding the foreign key instead"))
+	}
+}
+
+// analyzeManyToMany pairs the list fields of the models and checks that the
+// join tables do not clash with a model.
+func (a *Analyzer) analyzeManyToMany() {
+	tables, diags := resolveManyToMany(a.ast)
+	a.diagnostics = append(a.diagnostics, diags...)
+
+	for _, table := range tables {
+		existing, ok := a.symbols.Lookup(table.Name)
+		if !ok {
+			continue
+		}
+
+		relation := decorator.ForField(table.A.Field).Relation
+		if relation == nil {
+			relation = decorator.ForField(table.B.Field).Relation
+		}
+
+		token := table.A.Field.Identifier.Token
+		if relation != nil {
+			token = relation.Decorator.Name.Token
+		}
+
+		diag := diagnostic.Errorf(CodeDuplicateSymbol, token, "join table %s has the same name as %s", table.Name, existing.Name)
+		a.report(diag.WithHint("name the relation with @relation(name: \"...\")"))
+	}
+}
+
+// analyzeType reports whether the type of the declaration resolves.
+func (a *Analyzer) analyzeType(item *ast.Declaration) bool {
+	declType := item.DeclarationType
+	if declType.Type != ast.VariableTypeObject {
+		return true
+	}
+
+	if _, ok := a.symbols.Resolve(declType); !ok {
+		diag := diagnostic.Errorf(CodeUnknownType, declType.Token, "unknown type %s", declType.Name)
+		a.report(diag.WithHint("a type is int, real, bool, string, DateTime or the name of a model or enum"))
+		return false
+	}
+
+	return true
+}
+
+// analyzeDecorators checks the decorators of the declaration against the
+// decorator registry.
+func (a *Analyzer) analyzeDecorators(item *ast.Declaration) {
+	target := a.targetOf(item.DeclarationType)
+	seen := map[string]*ast.Decorator{}
+
+	for _, dec := range item.Decorators {
+		if existing, ok := seen[dec.Name.Identifier]; ok {
+			diag := diagnostic.Errorf(decorator.CodeDuplicateDecorator, dec.Name.Token, "@%s is already applied to %s", dec.Name.Identifier, item.Identifier.Identifier)
+			a.report(diag.WithHint("previous @" + dec.Name.Identifier + " is at " + diagnostic.Location(existing.Name.Token)))
+			continue
+		}
+		seen[dec.Name.Identifier] = dec
+
+		a.diagnostics = append(a.diagnostics, decorator.Check(dec, target)...)
+	}
+}
+
+func (a *Analyzer) targetOf(declType *ast.DeclarationType) decorator.Target {
+	switch declType.Type {
+	case ast.VariableTypeInt:
+		return decorator.TargetInt
+	case ast.VariableTypeReal:
+		return decorator.TargetReal
+	case ast.VariableTypeBool:
+		return decorator.TargetBool
+	case ast.VariableTypeString:
+		return decorator.TargetString
+	case ast.VariableTypeDateTime:
+		return decorator.TargetDateTime
+	}
+
+	symbol, _ := a.symbols.Resolve(declType)
+	if symbol.Kind == SymbolKindEnum {
+		return decorator.TargetEnum
+	}
+
+	if declType.IsArray {
+		return decorator.TargetModelList
+	}
+
+	return decorator.TargetModel
+}
+
+func (a *Analyzer) analyzeRelation(model *ast.Model, item *ast.Declaration, relation *decorator.Relation) {
+	target, ok := a.symbols.Resolve(item.DeclarationType)
+	if !ok {
+		return
+	}
+
+	owner, ok := a.symbols.Lookup(model.Name.Identifier)
+	if !ok || owner.Model != model {
+		return
+	}
+
+	args, _ := decorator.Bind(relation.Decorator)
+
+	var field *ast.Declaration
+	if arg, ok := args["field"]; ok {
+		field, ok = owner.Field(relation.Field)
+		if !ok {
+			a.report(diagnostic.Errorf(CodeUnknownField, arg.Value.Token, "model %s has no field %s", owner.Name, relation.Field))
+		} else if decorator.ForField(field).Nullable == nil {
+			for _, name := range []string{"onDelete", "onUpdate"} {
+				action, ok := args[name]
+				if ok && decorator.ValueOf(action) == decorator.ActionSetNull {
+					diag := diagnostic.Errorf(CodeNotNullable, action.Value.Token, "%s: SetNull requires field %s to be @nullable", name, relation.Field)
+					a.report(diag.WithHint("add @nullable to " + relation.Field))
+				}
+			}
+		}
+	}
+
+	if arg, ok := args["reference"]; ok && target.Kind == SymbolKindModel {
+		reference, ok := target.Field(relation.Reference)
+		if !ok {
+			a.report(diagnostic.Errorf(CodeUnknownField, arg.Value.Token, "model %s has no field %s", target.Name, relation.Reference))
+		} else if field != nil && !sameType(field.DeclarationType, reference.DeclarationType) {
+			diag := diagnostic.Errorf(CodeTypeMismatch, args["field"].Value.Token, "field %s is %s but %s.%s is %s", relation.Field, typeName(field.DeclarationType), target.Name, relation.Reference, typeName(reference.DeclarationType))
+			a.report(diag.WithHint("change the type of " + relation.Field + " to " + typeName(reference.DeclarationType)))
+		}
+	}
+}
+
+// sameType reports whether a foreign key column of type a can reference a
+// column of type b.
+func sameType(a *ast.DeclarationType, b *ast.DeclarationType) bool {
+	return a.Type == b.Type && a.Name == b.Name && a.IsArray == b.IsArray
+}
+
+func typeName(declType *ast.DeclarationType) string {
+	if declType.IsArray {
+		return declType.Name + "[]"
+	}
+
+	return declType.Name
+}
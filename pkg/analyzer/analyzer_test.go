@@ -0,0 +1,420 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func TestAnalyzer(t *testing.T) {
+	input := `
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model User {
+  id      string  @id @default(uuid())
+  name    string
+  role    Role
+  posts   Post[]
+}
+
+model Post {
+  id        int     @id @default(autoincrement())
+  title     string
+  author    User    @relation(field: authorId, reference: id)
+  authorId  string
+}`
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	symbols, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("analyzer error: %s", err.Error())
+	}
+
+	posts, _ := symbols.Lookup("User")
+	field, ok := posts.Field("posts")
+	if !ok {
+		t.Fatalf("expected field posts in User")
+	}
+
+	symbol, ok := symbols.Resolve(field.DeclarationType)
+	if !ok || symbol.Model != ast.Models[1] {
+		t.Fatalf("expected posts to resolve to model Post")
+	}
+
+	field, _ = posts.Field("role")
+	symbol, ok = symbols.Resolve(field.DeclarationType)
+	if !ok || symbol.Enum != ast.Enums[0] {
+		t.Fatalf("expected role to resolve to enum Role")
+	}
+}
+
+func TestAnalyzerDiagnostics(t *testing.T) {
+	input := `
+enum Role {
+  admin = "admin"
+  user = 1
+}
+
+enum Empty {
+}
+
+model User {
+  id      string  @id
+  name    string  @id
+  name    string
+  role    Rol
+}
+
+model Post {
+  title     string
+  author    User    @relation(field: authorId, reference: uid)
+}
+
+model User {
+  id string @id
+}`
+
+	expected := []struct {
+		code string
+		row  int
+		col  int
+	}{
+		{analyzer.CodeDuplicateField, 12, 2},
+		{analyzer.CodeDuplicateSymbol, 21, 6},
+		{analyzer.CodeMixedEnumValues, 3, 9},
+		{analyzer.CodeEmptyEnum, 6, 5},
+		{analyzer.CodeMultipleId, 11, 19},
+		{analyzer.CodeUnknownType, 13, 10},
+		{analyzer.CodeUnknownField, 18, 37},
+		{analyzer.CodeUnknownField, 18, 58},
+		{analyzer.CodeMissingId, 16, 6},
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err == nil {
+		t.Fatalf("expected analyzer error")
+	}
+
+	diags := analyzer.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d:\n%s", len(expected), len(diags), err.Error())
+	}
+
+	for i, exp := range expected {
+		diag := diags[i]
+		if diag.Code != exp.code || diag.Start.Row != exp.row || diag.Start.Col != exp.col {
+			t.Fatalf("expected %s at %d:%d but got %s", exp.code, exp.row, exp.col, diag.Error())
+		}
+	}
+}
+
+func TestAnalyzerRelationType(t *testing.T) {
+	input := `
+model User {
+  id      string  @id
+  posts   Post[]
+}
+
+model Post {
+  id        int     @id
+  author    User    @relation(field: authorId, reference: id)
+  authorId  int
+}`
+	expectedCode := analyzer.CodeTypeMismatch
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err == nil {
+		t.Fatalf("expected analyzer error")
+	}
+
+	diags := analyzer.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != expectedCode || diags[0].Start.Row != 8 || diags[0].Start.Col != 37 {
+		t.Fatalf("expected a type mismatch at 8:37 but got:\n%s", err.Error())
+	}
+}
+
+func TestAnalyzerNaming(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+		row   int
+		col   int
+	}{
+		{"db {\n  naming = \"kebab\"\n}\n\nmodel User {\n  id int @id\n}", analyzer.CodeInvalidConfig, 1, 11},
+		{"db {\n  naming = \"snake\"\n}\n\nmodel User {\n  id int @id\n  userId int\n  user_id int\n}", analyzer.CodeDuplicateField, 7, 2},
+		{"db {\n  naming = \"snake_plural\"\n}\n\nmodel Category {\n  id int @id\n}\n\nmodel Tag {\n  id int @id\n  @@map(\"categories\")\n}", analyzer.CodeDuplicateSymbol, 8, 6},
+	}
+
+	for _, test := range tests {
+		ast, err := parser.NewParser(lexer.NewLexer(test.input)).Parse()
+		if err != nil {
+			t.Fatalf("parser error: %s", err.Error())
+		}
+
+		analyzer := analyzer.NewAnalyzer(ast)
+
+		_, err = analyzer.Analyze()
+		if err == nil {
+			t.Fatalf("expected analyzer error for:\n%s", test.input)
+		}
+
+		diags := analyzer.Diagnostics()
+		if len(diags) != 1 || diags[0].Code != test.code || diags[0].Start.Row != test.row || diags[0].Start.Col != test.col {
+			t.Fatalf("expected %s at %d:%d but got %s", test.code, test.row, test.col, err.Error())
+		}
+	}
+}
+
+func TestAnalyzerConfig(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+		row   int
+		col   int
+	}{
+		{"db {\n  nullable = \"maybe\"\n}\n\nmodel User {\n  id int @id\n}", analyzer.CodeInvalidConfig, 1, 13},
+		{"db {\n  context = \"yes\"\n}\n\nmodel User {\n  id int @id\n}", analyzer.CodeInvalidConfig, 1, 12},
+	}
+
+	for _, test := range tests {
+		ast, err := parser.NewParser(lexer.NewLexer(test.input)).Parse()
+		if err != nil {
+			t.Fatalf("parser error: %s", err.Error())
+		}
+
+		analyzer := analyzer.NewAnalyzer(ast)
+
+		_, err = analyzer.Analyze()
+		if err == nil {
+			t.Fatalf("expected analyzer error for:\n%s", test.input)
+		}
+
+		diags := analyzer.Diagnostics()
+		if len(diags) != 1 || diags[0].Code != test.code || diags[0].Start.Row != test.row || diags[0].Start.Col != test.col {
+			t.Fatalf("expected %s at %d:%d but got %s", test.code, test.row, test.col, err.Error())
+		}
+	}
+}
+
+func TestAnalyzerDecorators(t *testing.T) {
+	input := `
+enum Role {
+  admin = "admin"
+  guest = true
+}
+
+model User {
+  id      string  @id @default(uuid())
+  role    Role    @default("admin") @default("user")
+  active  bool    @default(1)
+  name    string  @default(null)
+  nick    string  @default(null) @nullable
+  posts   Post[]  @unique
+}
+
+model Post {
+  id      int     @id
+  author  User    @relation(field: id, reference: id, cascade: true)
+  editor  User    @relation(field: editorId, reference: id, onDelete: Drop)
+  editorId string
+  owner   User    @relation(field: ownerId, reference: id, onDelete: SetNull)
+  ownerId string
+
+  @@unique([editorId, missing])
+  @@unique([owner])
+  @@unique("editorId")
+  @@search([editorId])
+}`
+
+	expected := []string{
+		analyzer.CodeInvalidEnumValue,
+		decorator.CodeDuplicateDecorator,
+		decorator.CodeInvalidValue,
+		analyzer.CodeNotNullable,
+		decorator.CodeInvalidTarget,
+		decorator.CodeUnknownArgument,
+		decorator.CodeInvalidValue,
+		analyzer.CodeNotNullable,
+		decorator.CodeInvalidArgumentKind,
+		decorator.CodeUnknownDecorator,
+		analyzer.CodeUnknownField,
+		analyzer.CodeUnknownField,
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err == nil {
+		t.Fatalf("expected analyzer error")
+	}
+
+	diags := analyzer.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d:\n%s", len(expected), len(diags), err.Error())
+	}
+
+	for i, code := range expected {
+		if diags[i].Code != code {
+			t.Fatalf("expected %s but got %s", code, diags[i].Error())
+		}
+	}
+}
+
+func TestAnalyzerCursor(t *testing.T) {
+	input := `
+model Post {
+  id        int      @id
+  createdAt DateTime
+  deletedAt DateTime @nullable
+
+  @@cursor([createdAt, deletedAt, missing])
+}`
+
+	expected := []string{analyzer.CodeNotNullable, analyzer.CodeUnknownField}
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err == nil {
+		t.Fatalf("expected analyzer error")
+	}
+
+	diags := analyzer.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d:\n%s", len(expected), len(diags), err.Error())
+	}
+
+	for i, code := range expected {
+		if diags[i].Code != code {
+			t.Fatalf("expected %s but got %s", code, diags[i].Error())
+		}
+	}
+}
+
+func TestAnalyzerManyToMany(t *testing.T) {
+	input := `
+model Post {
+  id      int     @id
+  tags    Tag[]
+  editors User[]  @relation(name: "PostEditors")
+  readers User[]
+  likes   User[]
+}
+
+model Tag {
+  id      string  @id
+  posts   Post[]
+}
+
+model User {
+  id       int     @id
+  edits    Post[]  @relation(name: "PostEditors")
+  reads    Post[]
+  follows  User[]  @relation(name: "Follows")
+  followed User[]  @relation(name: "Follows")
+  drafts   Post[]  @relation(name: "Drafts")
+}`
+
+	expectedTables := []string{"_PostToTag", "PostEditors", "Follows"}
+	expected := []struct {
+		code string
+		row  int
+		col  int
+	}{
+		{analyzer.CodeAmbiguousRelation, 6, 2},
+		{analyzer.CodeAmbiguousRelation, 5, 2},
+		{analyzer.CodeAmbiguousRelation, 17, 2},
+		{analyzer.CodeUnmatchedRelation, 20, 2},
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	tables := analyzer.JoinTables(ast)
+	if len(tables) != len(expectedTables) {
+		t.Fatalf("expected %d join tables but found %d", len(expectedTables), len(tables))
+	}
+
+	for i, name := range expectedTables {
+		if tables[i].Name != name {
+			t.Fatalf("expected join table %s but got %s", name, tables[i].Name)
+		}
+	}
+
+	if tables[0].A.Column != "postId" || tables[0].B.Column != "tagId" {
+		t.Fatalf("unexpected columns %s, %s", tables[0].A.Column, tables[0].B.Column)
+	}
+
+	if tables[2].A.Column != "followedId" || tables[2].B.Column != "followsId" {
+		t.Fatalf("unexpected self relation columns %s, %s", tables[2].A.Column, tables[2].B.Column)
+	}
+
+	analyzer := analyzer.NewAnalyzer(ast)
+
+	_, err = analyzer.Analyze()
+	if err == nil {
+		t.Fatalf("expected analyzer error")
+	}
+
+	diags := analyzer.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d:\n%s", len(expected), len(diags), err.Error())
+	}
+
+	for i, exp := range expected {
+		diag := diags[i]
+		if diag.Code != exp.code || diag.Start.Row != exp.row || diag.Start.Col != exp.col {
+			t.Fatalf("expected %s at %d:%d but got %s", exp.code, exp.row, exp.col, diag.Error())
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+)
+
+// RelationEnd is one side of a many-to-many relation, the list field and the
+// model declaring it.
+type RelationEnd struct {
+	Model *ast.Model
+	Field *ast.Declaration
+	// Column is the column of the join table holding the id of Model.
+	Column string
+}
+
+// JoinTable links the two sides of a many-to-many relation. It is named by
+// @relation(name: ...) or after both models, e.g. _PostToTag or with snake
+// case naming _post_to_tag.
+type JoinTable struct {
+	Name string
+	A    *RelationEnd
+	B    *RelationEnd
+}
+
+// Other returns the opposite side of the relation.
+func (t *JoinTable) Other(end *RelationEnd) *RelationEnd {
+	if end == t.A {
+		return t.B
+	}
+
+	return t.A
+}
+
+// End returns the side of the relation declared by the field.
+func (t *JoinTable) End(field *ast.Declaration) (*RelationEnd, bool) {
+	if t.A.Field == field {
+		return t.A, true
+	}
+	if t.B.Field == field {
+		return t.B, true
+	}
+
+	return nil, false
+}
+
+// JoinTables returns the join tables of every many-to-many relation of the
+// ast, i.e. of every pair of list fields referencing each other's model.
+// Ambiguous lists are skipped, they are reported by the analyzer.
+func JoinTables(ast *ast.Ast) []*JoinTable {
+	tables, _ := resolveManyToMany(ast)
+	return tables
+}
+
+type relationGroup struct {
+	name string
+	ends []*RelationEnd
+}
+
+// resolveManyToMany pairs list fields by the models they connect and the
+// name of their relation. A pair with one list on each side is a
+// many-to-many relation, a single list is the inverse side of a foreign
+// key. Join tables and their columns are named after the naming of the
+// schema.
+func resolveManyToMany(schema *ast.Ast) ([]*JoinTable, diagnostic.Diagnostics) {
+	models := schema.Models
+	naming := decorator.NamingOf(schema)
+
+	byName := map[string]*ast.Model{}
+	for _, model := range models {
+		byName[model.Name.Identifier] = model
+	}
+
+	groups := []*relationGroup{}
+	byKey := map[string]*relationGroup{}
+
+	for _, model := range models {
+		for _, item := range model.Items {
+			if !item.DeclarationType.IsArray {
+				continue
+			}
+
+			target, ok := byName[item.DeclarationType.Name]
+			if !ok {
+				continue
+			}
+
+			name := ""
+			if relation := decorator.ForField(item).Relation; relation != nil {
+				name = relation.Name
+			}
+
+			pair := []string{model.Name.Identifier, target.Name.Identifier}
+			sort.Strings(pair)
+			key := pair[0] + "\x00" + pair[1] + "\x00" + name
+
+			group, ok := byKey[key]
+			if !ok {
+				group = &relationGroup{name: name}
+				byKey[key] = group
+				groups = append(groups, group)
+			}
+
+			group.ends = append(group.ends, &RelationEnd{Model: model, Field: item})
+		}
+	}
+
+	tables := []*JoinTable{}
+	diags := diagnostic.Diagnostics{}
+
+	for _, group := range groups {
+		ends := group.ends
+		sort.SliceStable(ends, func(i, j int) bool {
+			if ends[i].Model.Name.Identifier != ends[j].Model.Name.Identifier {
+				return ends[i].Model.Name.Identifier < ends[j].Model.Name.Identifier
+			}
+			return ends[i].Field.Identifier.Identifier < ends[j].Field.Identifier.Identifier
+		})
+
+		switch classify(ends) {
+		case relationAmbiguous:
+			diags = append(diags, ambiguous(ends)...)
+			continue
+		case relationInverse:
+			if group.name != "" {
+				for _, end := range ends {
+					diag := diagnostic.Errorf(CodeUnmatchedRelation, end.Field.Identifier.Token, "relation %s has no list on the other side", group.name)
+					diags = append(diags, diag.WithHint("add @relation(name: \""+group.name+"\") to a "+end.Model.Name.Identifier+"[] field of "+end.Field.DeclarationType.Name))
+				}
+			}
+			continue
+		}
+
+		table := JoinTable{Name: group.name, A: ends[0], B: ends[1]}
+		if table.Name == "" {
+			table.Name = naming.Column("_" + table.A.Model.Name.Identifier + "To" + table.B.Model.Name.Identifier)
+		}
+
+		if table.A.Model == table.B.Model {
+			table.A.Column = naming.Column(table.A.Field.Identifier.Identifier + "Id")
+			table.B.Column = naming.Column(table.B.Field.Identifier.Identifier + "Id")
+		} else {
+			table.A.Column = naming.Column(uncapitalize(table.A.Model.Name.Identifier) + "Id")
+			table.B.Column = naming.Column(uncapitalize(table.B.Model.Name.Identifier) + "Id")
+		}
+
+		tables = append(tables, &table)
+	}
+
+	return tables, diags
+}
+
+type relationKind int
+
+const (
+	relationManyToMany relationKind = iota
+	relationInverse
+	relationAmbiguous
+)
+
+// classify tells whether lists connecting the same models form a
+// many-to-many relation, are only inverse sides of foreign keys or can not
+// be paired.
+func classify(ends []*RelationEnd) relationKind {
+	owner := ends[0].Model
+	if owner.Name.Identifier == ends[0].Field.DeclarationType.Name {
+		// a self relation has both ends in the same model
+		switch {
+		case len(ends) == 2:
+			return relationManyToMany
+		case len(ends) > 2:
+			return relationAmbiguous
+		}
+		return relationInverse
+	}
+
+	sides := map[*ast.Model]int{}
+	for _, end := range ends {
+		sides[end.Model]++
+	}
+
+	switch {
+	case len(sides) < 2:
+		return relationInverse
+	case len(ends) == 2:
+		return relationManyToMany
+	}
+	return relationAmbiguous
+}
+
+func ambiguous(ends []*RelationEnd) diagnostic.Diagnostics {
+	diags := diagnostic.Diagnostics{}
+
+	for _, end := range ends {
+		diag := diagnostic.Errorf(CodeAmbiguousRelation, end.Field.Identifier.Token, "ambiguous relation between %s and %s", end.Model.Name.Identifier, end.Field.DeclarationType.Name)
+		diags = append(diags, diag.WithHint("name both sides of each relation with @relation(name: \"...\")"))
+	}
+
+	return diags
+}
+
+// uncapitalize lower cases the first letter of a name, the analyzer is a
+// public package and does not depend on internal/utils.
+func uncapitalize(name string) string {
+	if name == "" {
+		return name
+	}
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
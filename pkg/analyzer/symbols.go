@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+type SymbolKind int
+
+const (
+	SymbolKindModel SymbolKind = iota
+	SymbolKindEnum
+)
+
+type Symbol struct {
+	Kind  SymbolKind
+	Name  string
+	Model *ast.Model
+	Enum  *ast.Enum
+
+	fields map[string]*ast.Declaration
+}
+
+func newModelSymbol(model *ast.Model) *Symbol {
+	s := Symbol{
+		Kind:   SymbolKindModel,
+		Name:   model.Name.Identifier,
+		Model:  model,
+		fields: map[string]*ast.Declaration{},
+	}
+
+	return &s
+}
+
+func newEnumSymbol(enum *ast.Enum) *Symbol {
+	s := Symbol{
+		Kind: SymbolKindEnum,
+		Name: enum.Name.Identifier,
+		Enum: enum,
+	}
+
+	return &s
+}
+
+// Token returns the token of the name the symbol was declared with.
+func (s *Symbol) Token() *lexer.Token {
+	if s.Kind == SymbolKindModel {
+		return s.Model.Name.Token
+	}
+
+	return s.Enum.Name.Token
+}
+
+// Field returns the field of a model symbol.
+func (s *Symbol) Field(name string) (*ast.Declaration, bool) {
+	field, ok := s.fields[name]
+	return field, ok
+}
+
+// SymbolTable holds every model and enum of an ast by name.
+type SymbolTable struct {
+	symbols map[string]*Symbol
+	order   []*Symbol
+}
+
+func NewSymbolTable() *SymbolTable {
+	t := SymbolTable{
+		symbols: map[string]*Symbol{},
+	}
+
+	return &t
+}
+
+// Add adds the symbol to the table. If a symbol with the same name already
+// exists it is returned and the table is left unchanged.
+func (t *SymbolTable) Add(symbol *Symbol) (*Symbol, bool) {
+	existing, ok := t.symbols[symbol.Name]
+	if ok {
+		return existing, false
+	}
+
+	t.symbols[symbol.Name] = symbol
+	t.order = append(t.order, symbol)
+
+	return symbol, true
+}
+
+func (t *SymbolTable) Lookup(name string) (*Symbol, bool) {
+	symbol, ok := t.symbols[name]
+	return symbol, ok
+}
+
+// Resolve returns the model or enum referenced by a declaration type. Types
+// which are not objects never resolve.
+func (t *SymbolTable) Resolve(declType *ast.DeclarationType) (*Symbol, bool) {
+	if declType.Type != ast.VariableTypeObject {
+		return nil, false
+	}
+
+	return t.Lookup(declType.Name)
+}
+
+// Symbols returns every symbol in declaration order.
+func (t *SymbolTable) Symbols() []*Symbol {
+	return t.order
+}
@@ -1,9 +1,14 @@
 package ast
 
+import "strings"
+
 type Ast struct {
-	Config []*Config
-	Enums  []*Enum
-	Models []*Model
+	Imports []*Import
+	Config  []*Config
+	Enums   []*Enum
+	Models  []*Model
+	// Comments are the comments after the last block.
+	Comments []*Comment
 }
 
 func NewAst() *Ast {
@@ -16,6 +21,73 @@ func NewAst() *Ast {
 	return &ast
 }
 
+// String prints the imports, the config blocks, then the enums and models,
+// separated by an empty line.
+func (a *Ast) String() string {
+	blocks := []string{}
+
+	if len(a.Imports) > 0 {
+		var sb strings.Builder
+		for _, imp := range a.Imports {
+			writeComments(&sb, imp.Comments, "", row(imp.Token))
+			sb.WriteString(imp.String())
+			if imp.Trailing != nil {
+				sb.WriteString(" ")
+				sb.WriteString(imp.Trailing.String())
+			}
+			sb.WriteString("\n")
+		}
+		blocks = append(blocks, sb.String())
+	}
+
+	for _, config := range a.Config {
+		blocks = append(blocks, config.String())
+	}
+
+	for _, enum := range a.Enums {
+		blocks = append(blocks, enum.String())
+	}
+
+	for _, model := range a.Models {
+		blocks = append(blocks, model.String())
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(blocks, "\n"))
+	if len(blocks) > 0 && len(a.Comments) > 0 {
+		sb.WriteString("\n")
+	}
+	writeComments(&sb, a.Comments, "", -1)
+
+	return sb.String()
+}
+
+// Merge adds the imports and blocks of another file.
+func (a *Ast) Merge(other *Ast) {
+	a.Imports = append(a.Imports, other.Imports...)
+	a.Config = append(a.Config, other.Config...)
+	a.Enums = append(a.Enums, other.Enums...)
+	a.Models = append(a.Models, other.Models...)
+}
+
 func (a *Ast) AddConfig(config *Config) {
 	a.Config = append(a.Config, config)
 }
+
+// ConfigItem returns the item assigned to name in the config blocks of the
+// given type, e.g. ConfigItem("db", "provider").
+func (a *Ast) ConfigItem(configType string, name string) (*AssignItem, bool) {
+	for _, config := range a.Config {
+		if config.Type != configType {
+			continue
+		}
+
+		for _, item := range config.Items {
+			if item.Identifier.Identifier == name {
+				return item, true
+			}
+		}
+	}
+
+	return nil, false
+}
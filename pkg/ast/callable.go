@@ -2,6 +2,8 @@ package ast
 
 import (
 	"strings"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
 )
 
 type ArgumentType int
@@ -21,6 +23,8 @@ type Argument struct {
 type Callable struct {
 	Identifier *Identifier
 	Arguments  []*Argument
+	// End is the ) token closing the arguments.
+	End *lexer.Token
 }
 
 func NewCallable(identifier *Identifier) *Callable {
@@ -79,7 +83,7 @@ func (a *Argument) String() string {
 		sb.WriteString(": ")
 	}
 
-	sb.WriteString(a.Value.Value)
+	sb.WriteString(a.Value.String())
 
 	return sb.String()
 }
@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+type Comment struct {
+	Token *lexer.Token
+	Text  string
+}
+
+func NewComment(token *lexer.Token) *Comment {
+	text := strings.TrimPrefix(token.Literal, "///")
+	text = strings.TrimPrefix(text, " ")
+
+	c := Comment{
+		Token: token,
+		Text:  text,
+	}
+
+	return &c
+}
+
+func (c *Comment) String() string {
+	return c.Token.Literal
+}
@@ -10,6 +10,10 @@ type Config struct {
 	Token *lexer.Token
 	Type  string
 	Items []*AssignItem
+	// Comments are the comments before the block, EndComments the comments
+	// after its last item.
+	Comments    []*Comment
+	EndComments []*Comment
 }
 
 func NewConfig(token *lexer.Token) *Config {
@@ -24,13 +28,18 @@ func NewConfig(token *lexer.Token) *Config {
 
 func (c *Config) String() string {
 	var sb strings.Builder
+
+	writeComments(&sb, c.Comments, "", row(c.Token))
 	sb.WriteString(c.Type)
 	sb.WriteString(" {\n")
-	for _, item := range c.Items {
-		sb.WriteString(item.String())
-		sb.WriteString("\n")
+
+	lines := make([]line, len(c.Items))
+	for i, item := range c.Items {
+		lines[i] = item.line()
 	}
-	sb.WriteString(" }\n")
+	writeBlock(&sb, lines, c.EndComments)
+
+	sb.WriteString("}\n")
 
 	return sb.String()
 }
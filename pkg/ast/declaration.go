@@ -30,6 +30,11 @@ type Declaration struct {
 	Identifier      *Identifier
 	DeclarationType *DeclarationType
 	Decorators      []*Decorator
+	Doc             []*Comment
+	// Comments are the comments before the field, including Doc, and
+	// Trailing the comment after it on the same line.
+	Comments []*Comment
+	Trailing *Comment
 }
 
 type DeclarationType struct {
@@ -54,10 +59,35 @@ func (d *Declaration) String() string {
 	sb.WriteString(d.Identifier.String())
 	sb.WriteString(" ")
 	sb.WriteString(d.DeclarationType.String())
+	for _, dec := range d.Decorators {
+		sb.WriteString(" ")
+		sb.WriteString(dec.String())
+	}
 
 	return sb.String()
 }
 
+func (d *Declaration) line() line {
+	l := line{
+		comments: leading(d.Comments, d.Doc),
+		cells:    []string{d.Identifier.String(), d.DeclarationType.String()},
+		trailing: d.Trailing,
+		row:      row(d.Identifier.Token),
+		end:      max(row(d.Identifier.Token), row(d.DeclarationType.Token)),
+	}
+
+	if len(d.Decorators) > 0 {
+		decs := make([]string, len(d.Decorators))
+		for i, dec := range d.Decorators {
+			decs[i] = dec.String()
+			l.end = max(l.end, dec.endRow())
+		}
+		l.cells = append(l.cells, strings.Join(decs, " "))
+	}
+
+	return l
+}
+
 func NewDeclarationType(token *lexer.Token, isArray bool) *DeclarationType {
 	vType := VariableTypeMap[token.Type]
 
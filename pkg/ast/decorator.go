@@ -18,6 +18,10 @@ type Decorator struct {
 	Type     DecoratorType
 	Name     *Identifier
 	Callable *Callable
+	// Comments are the comments before a @@ decorator and Trailing the
+	// comment after it on the same line.
+	Comments []*Comment
+	Trailing *Comment
 }
 
 func NewDecorator(token *lexer.Token, name *Identifier) *Decorator {
@@ -48,3 +52,23 @@ func (d *Decorator) SetCallable(callable *Callable) {
 	d.Type = DecoratorTypeCallable
 	d.Callable = callable
 }
+
+func (d *Decorator) line() line {
+	l := line{
+		comments: d.Comments,
+		cells:    []string{d.String()},
+		trailing: d.Trailing,
+		row:      row(d.Token),
+		end:      d.endRow(),
+	}
+
+	return l
+}
+
+func (d *Decorator) endRow() int {
+	if d.Type == DecoratorTypeCallable && d.Callable.End != nil {
+		return max(row(d.Token), row(d.Callable.End))
+	}
+
+	return max(row(d.Token), row(d.Name.Token))
+}
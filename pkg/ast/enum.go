@@ -10,6 +10,11 @@ type Enum struct {
 	Token *lexer.Token
 	Name  *Identifier
 	Items []*AssignItem
+	Doc   []*Comment
+	// Comments are the comments before the block, including Doc, and
+	// EndComments the comments after its last item.
+	Comments    []*Comment
+	EndComments []*Comment
 }
 
 func NewEnum(token *lexer.Token, ident *Identifier) *Enum {
@@ -24,14 +29,19 @@ func NewEnum(token *lexer.Token, ident *Identifier) *Enum {
 
 func (e *Enum) String() string {
 	var sb strings.Builder
+
+	writeComments(&sb, leading(e.Comments, e.Doc), "", row(e.Token))
 	sb.WriteString("enum ")
 	sb.WriteString(e.Name.Identifier)
 	sb.WriteString(" {\n")
-	for _, item := range e.Items {
-		sb.WriteString(item.String())
-		sb.WriteString("\n")
+
+	lines := make([]line, len(e.Items))
+	for i, item := range e.Items {
+		lines[i] = item.line()
 	}
-	sb.WriteString(" }\n")
+	writeBlock(&sb, lines, e.EndComments)
+
+	sb.WriteString("}\n")
 
 	return sb.String()
 }
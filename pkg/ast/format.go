@@ -0,0 +1,146 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+const indent = "  "
+
+// line is an item of a block. Its cells are aligned in columns with the
+// cells of the lines around it, like gofmt aligns the fields of a struct.
+type line struct {
+	comments []*Comment
+	cells    []string
+	trailing *Comment
+	// row and end are the rows of the first and last token of the item.
+	row int
+	end int
+	// separated puts an empty line before the item.
+	separated bool
+}
+
+// writeBlock writes the lines of a block body. An empty line in the source
+// between two items is kept and ends the columns the items are aligned in.
+func writeBlock(sb *strings.Builder, lines []line, endComments []*Comment) {
+	section := []line{}
+	for i, l := range lines {
+		if i > 0 && (l.separated || isSeparated(lines[i-1].end, startRow(l.comments, l.row))) {
+			writeSection(sb, section)
+			sb.WriteString("\n")
+			section = []line{}
+		}
+
+		section = append(section, l)
+	}
+	writeSection(sb, section)
+
+	if len(lines) > 0 && len(endComments) > 0 && isSeparated(lines[len(lines)-1].end, endComments[0].Token.Row) {
+		sb.WriteString("\n")
+	}
+	writeComments(sb, endComments, indent, -1)
+}
+
+func writeSection(sb *strings.Builder, lines []line) {
+	widths := []int{}
+	for _, l := range lines {
+		for i, cell := range l.cells[:len(l.cells)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	texts := make([]string, len(lines))
+	commentWidth := 0
+	for i, l := range lines {
+		var text strings.Builder
+		for j, cell := range l.cells {
+			if j < len(l.cells)-1 {
+				text.WriteString(pad(cell, widths[j]))
+				text.WriteString(" ")
+			} else {
+				text.WriteString(cell)
+			}
+		}
+
+		texts[i] = text.String()
+		if l.trailing != nil {
+			commentWidth = max(commentWidth, len(texts[i]))
+		}
+	}
+
+	for i, l := range lines {
+		writeComments(sb, l.comments, indent, l.row)
+
+		sb.WriteString(indent)
+		if l.trailing != nil {
+			sb.WriteString(pad(texts[i], commentWidth))
+			sb.WriteString(" ")
+			sb.WriteString(l.trailing.String())
+		} else {
+			sb.WriteString(texts[i])
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeComments writes comments on their own lines, keeping an empty line
+// between them and before the row they lead to.
+func writeComments(sb *strings.Builder, comments []*Comment, prefix string, next int) {
+	for i, comment := range comments {
+		if i > 0 && isSeparated(comments[i-1].Token.Row, comment.Token.Row) {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(prefix)
+		sb.WriteString(comment.String())
+		sb.WriteString("\n")
+	}
+
+	if len(comments) > 0 && next >= 0 && isSeparated(comments[len(comments)-1].Token.Row, next) {
+		sb.WriteString("\n")
+	}
+}
+
+// leading returns the comments written before a node. Nodes created
+// without a parser only have doc comments.
+func leading(comments []*Comment, doc []*Comment) []*Comment {
+	if len(comments) > 0 {
+		return comments
+	}
+
+	return doc
+}
+
+// startRow is the row of the first comment before a node, or of the node
+// itself.
+func startRow(comments []*Comment, row int) int {
+	if len(comments) > 0 {
+		return comments[0].Token.Row
+	}
+
+	return row
+}
+
+func row(token *lexer.Token) int {
+	if token == nil {
+		return -1
+	}
+
+	return token.Row
+}
+
+func isSeparated(end int, start int) bool {
+	return end >= 0 && start > end+1
+}
+
+func pad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return s + strings.Repeat(" ", width-len(s))
+}
@@ -0,0 +1,27 @@
+package ast
+
+import "github.com/gophoria/gophoria/pkg/lexer"
+
+// Import includes the blocks of another file, its path is relative to the
+// file containing the import.
+type Import struct {
+	Token *lexer.Token
+	Path  *Value
+	// Comments are the comments before the import and Trailing the comment
+	// after it on the same line.
+	Comments []*Comment
+	Trailing *Comment
+}
+
+func NewImport(token *lexer.Token, path *Value) *Import {
+	i := Import{
+		Token: token,
+		Path:  path,
+	}
+
+	return &i
+}
+
+func (i *Import) String() string {
+	return "import " + i.Path.String()
+}
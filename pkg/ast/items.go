@@ -2,6 +2,8 @@ package ast
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/gophoria/gophoria/pkg/lexer"
 )
@@ -11,8 +13,20 @@ type ValueType int
 const (
 	ValueTypeInt ValueType = iota
 	ValueTypeString
+	ValueTypeList
+	ValueTypeBool
+	ValueTypeReal
+	ValueTypeNull
 )
 
+var valueTypes = map[lexer.TokenType]ValueType{
+	lexer.TokenTypeInt:   ValueTypeInt,
+	lexer.TokenTypeReal:  ValueTypeReal,
+	lexer.TokenTypeTrue:  ValueTypeBool,
+	lexer.TokenTypeFalse: ValueTypeBool,
+	lexer.TokenTypeNull:  ValueTypeNull,
+}
+
 type Identifier struct {
 	Token      *lexer.Token
 	Identifier string
@@ -35,12 +49,16 @@ type Value struct {
 	Token *lexer.Token
 	Type  ValueType
 	Value string
+	// Items holds the values of a list.
+	Items []*Value
 }
 
+// NewValue creates a value of the type of the literal token, strings and
+// identifiers are both string values.
 func NewValue(token *lexer.Token) *Value {
-	valType := ValueTypeString
-	if token.Type == lexer.TokenTypeInt {
-		valType = ValueTypeInt
+	valType, ok := valueTypes[token.Type]
+	if !ok {
+		valType = ValueTypeString
 	}
 
 	v := Value{
@@ -52,9 +70,37 @@ func NewValue(token *lexer.Token) *Value {
 	return &v
 }
 
+// NewListValue creates an empty list starting at the [ token.
+func NewListValue(token *lexer.Token) *Value {
+	v := Value{
+		Token: token,
+		Type:  ValueTypeList,
+		Items: []*Value{},
+	}
+
+	return &v
+}
+
+func (v *Value) AddItem(item *Value) {
+	v.Items = append(v.Items, item)
+}
+
 func (v *Value) String() string {
-	if v.Type == ValueTypeString {
-		return "\"" + v.Value + "\""
+	if v.Type == ValueTypeList {
+		items := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			items[i] = item.String()
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	if v.Token.Type == lexer.TokenTypeString {
+		if v.Token.Raw != "" && v.Token.Literal == v.Value {
+			return v.Token.Raw
+		}
+
+		return strconv.Quote(v.Value)
 	}
 
 	return v.Value
@@ -64,6 +110,11 @@ type AssignItem struct {
 	Token      *lexer.Token
 	Identifier *Identifier
 	Value      *Value
+	Doc        []*Comment
+	// Comments are the comments before the item, including Doc, and Trailing
+	// the comment after it on the same line.
+	Comments []*Comment
+	Trailing *Comment
 }
 
 func NewAssignItem(token *lexer.Token, identifier *Identifier, value *Value) *AssignItem {
@@ -79,3 +130,15 @@ func NewAssignItem(token *lexer.Token, identifier *Identifier, value *Value) *As
 func (a *AssignItem) String() string {
 	return fmt.Sprintf("%s = %s", a.Identifier, a.Value)
 }
+
+func (a *AssignItem) line() line {
+	l := line{
+		comments: leading(a.Comments, a.Doc),
+		cells:    []string{a.String()},
+		trailing: a.Trailing,
+		row:      row(a.Identifier.Token),
+		end:      row(a.Value.Token),
+	}
+
+	return l
+}
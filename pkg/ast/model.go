@@ -1,11 +1,22 @@
 package ast
 
-import "github.com/gophoria/gophoria/pkg/lexer"
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
 
 type Model struct {
 	Token *lexer.Token
 	Name  *Identifier
 	Items []*Declaration
+	Doc   []*Comment
+	// Decorators are the @@ decorators applying to the whole model.
+	Decorators []*Decorator
+	// Comments are the comments before the block, including Doc, and
+	// EndComments the comments after its last item.
+	Comments    []*Comment
+	EndComments []*Comment
 }
 
 func NewModel(token *lexer.Token, name *Identifier) *Model {
@@ -21,6 +32,29 @@ func (m *Model) AddItem(item *Declaration) {
 	m.Items = append(m.Items, item)
 }
 
+// String prints the model with the names, types and decorators of its
+// fields aligned in columns and the @@ decorators after the fields.
 func (m *Model) String() string {
-	return ""
+	var sb strings.Builder
+
+	writeComments(&sb, leading(m.Comments, m.Doc), "", row(m.Token))
+	sb.WriteString("model ")
+	sb.WriteString(m.Name.Identifier)
+	sb.WriteString(" {\n")
+
+	lines := []line{}
+	for _, item := range m.Items {
+		lines = append(lines, item.line())
+	}
+
+	for i, dec := range m.Decorators {
+		l := dec.line()
+		l.separated = i == 0 && len(m.Items) > 0
+		lines = append(lines, l)
+	}
+	writeBlock(&sb, lines, m.EndComments)
+
+	sb.WriteString("}\n")
+
+	return sb.String()
 }
@@ -0,0 +1,218 @@
+package decorator_test
+
+import (
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func TestForField(t *testing.T) {
+	input := `
+model Post {
+  id        int     @id @default(autoincrement())
+  title     string  @unique @default("untitled")
+  content   string  @nullable
+  author    User    @relation(field: authorId, reference: id)
+  authorId  int
+}`
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	items := ast.Models[0].Items
+
+	id := decorator.ForField(items[0])
+	if id.Id == nil || id.Default == nil || !id.Default.IsFunction() || id.Default.Function != decorator.FunctionAutoincrement {
+		t.Fatalf("expected @id @default(autoincrement()) on id")
+	}
+
+	title := decorator.ForField(items[1])
+	if title.Unique == nil || title.Default == nil || title.Default.IsFunction() || title.Default.Value.Value != "untitled" {
+		t.Fatalf("expected @unique @default(\"untitled\") on title")
+	}
+
+	content := decorator.ForField(items[2])
+	if content.Nullable == nil || content.Id != nil || content.Default != nil {
+		t.Fatalf("expected only @nullable on content")
+	}
+
+	author := decorator.ForField(items[3])
+	if author.Relation == nil || author.Relation.Field != "authorId" || author.Relation.Reference != "id" {
+		t.Fatalf("expected @relation(field: authorId, reference: id) on author")
+	}
+}
+
+func TestForModel(t *testing.T) {
+	input := `
+model Membership {
+  userId  int     @map("user_id")
+  groupId int     @map("group_id")
+  role    string
+
+  @@id([userId, groupId])
+  @@index([groupId])
+  @@index([role, userId], name: "by_role")
+  @@cursor([role, userId, groupId])
+  @@map("memberships")
+}`
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	model := ast.Models[0]
+	decorators := decorator.ForModel(model)
+
+	if decorators.Id == nil || len(decorators.Id.Fields) != 2 || decorators.Id.Fields[1] != "groupId" {
+		t.Fatalf("expected @@id([userId, groupId])")
+	}
+
+	if len(decorators.Index) != 2 || decorators.Index[0].Name != "" || decorators.Index[1].Name != "by_role" || decorators.Index[1].Fields[0] != "role" {
+		t.Fatalf("expected @@index([groupId]) and @@index([role, userId], name: \"by_role\")")
+	}
+
+	if decorators.Cursor == nil || len(decorators.Cursor.Fields) != 3 || decorators.Cursor.Fields[0] != "role" {
+		t.Fatalf("expected @@cursor([role, userId, groupId])")
+	}
+
+	if decorator.TableName(ast, model) != "memberships" {
+		t.Fatalf("expected table memberships, got %s", decorator.TableName(ast, model))
+	}
+
+	columns := []string{"user_id", "group_id", "role"}
+	for i, item := range model.Items {
+		if decorator.ColumnName(ast, item) != columns[i] {
+			t.Fatalf("expected column %s, got %s", columns[i], decorator.ColumnName(ast, item))
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	input := `
+model Post {
+  a  int     @id
+  b  int     @default(autoincrement())
+  c  string  @default(autoincrement())
+  d  bool    @default(false)
+  e  bool    @default("false")
+  f  string  @serial
+  g  User    @relation(field: authorId, ref: id)
+  h  User    @relation(field: authorId)
+  i  User    @relation(field: "authorId", reference: id)
+  j  int     @default(random())
+  k  int     @default(1, 2)
+  l  DateTime @id
+}`
+
+	targets := []decorator.Target{
+		decorator.TargetInt,
+		decorator.TargetInt,
+		decorator.TargetString,
+		decorator.TargetBool,
+		decorator.TargetBool,
+		decorator.TargetString,
+		decorator.TargetModel,
+		decorator.TargetModel,
+		decorator.TargetModel,
+		decorator.TargetInt,
+		decorator.TargetInt,
+		decorator.TargetDateTime,
+	}
+
+	expected := []string{
+		"",
+		"",
+		decorator.CodeInvalidValue,
+		"",
+		decorator.CodeInvalidValue,
+		decorator.CodeUnknownDecorator,
+		decorator.CodeUnknownArgument,
+		decorator.CodeMissingArgument,
+		decorator.CodeInvalidArgumentKind,
+		decorator.CodeInvalidValue,
+		decorator.CodeUnknownArgument,
+		decorator.CodeInvalidTarget,
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	for i, item := range ast.Models[0].Items {
+		diags := decorator.Check(item.Decorators[0], targets[i])
+
+		if expected[i] == "" {
+			if len(diags) != 0 {
+				t.Fatalf("expected no diagnostics for %s but got %s", item.Identifier, diags.Error())
+			}
+			continue
+		}
+
+		if len(diags) != 1 || diags[0].Code != expected[i] {
+			t.Fatalf("expected %s for %s but got %v", expected[i], item.Identifier, diags)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	input := `
+db {
+  naming = "snake_plural"
+}
+
+model BlogPost {
+  id        int      @id
+  createdAt DateTime
+  userID    int      @map("owner")
+}
+
+model Category {
+  id        int      @id
+  HTTPStatus int
+}
+
+model Box {
+  id        int      @id
+  @@map("crates")
+}`
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	tables := []string{"blog_posts", "categories", "crates"}
+	for i, model := range ast.Models {
+		if table := decorator.TableName(ast, model); table != tables[i] {
+			t.Fatalf("expected table %s but got %s", tables[i], table)
+		}
+	}
+
+	columns := []string{"id", "created_at", "owner"}
+	for i, item := range ast.Models[0].Items {
+		if column := decorator.ColumnName(ast, item); column != columns[i] {
+			t.Fatalf("expected column %s but got %s", columns[i], column)
+		}
+	}
+
+	if column := decorator.ColumnName(ast, ast.Models[1].Items[1]); column != "http_status" {
+		t.Fatalf("expected column http_status but got %s", column)
+	}
+
+	ast.Config[0].Items[0].Value.Value = "unknown"
+	if table := decorator.TableName(ast, ast.Models[0]); table != "BlogPost" {
+		t.Fatalf("expected table BlogPost for an unknown naming but got %s", table)
+	}
+}
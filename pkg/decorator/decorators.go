@@ -0,0 +1,424 @@
+package decorator
+
+import (
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+const (
+	FunctionNow           = "now"
+	FunctionUuid          = "uuid"
+	FunctionAutoincrement = "autoincrement"
+	FunctionUlid          = "ulid" // generated by the client, columns have no default
+)
+
+const (
+	ActionCascade  = "Cascade"
+	ActionSetNull  = "SetNull"
+	ActionRestrict = "Restrict"
+	ActionNoAction = "NoAction"
+)
+
+var referentialActions = []string{ActionCascade, ActionSetNull, ActionRestrict, ActionNoAction}
+
+var defaultFunctionTargets = map[string]Target{
+	FunctionNow:           TargetDateTime,
+	FunctionUuid:          TargetString,
+	FunctionAutoincrement: TargetInt,
+	FunctionUlid:          TargetString,
+}
+
+var defaultLiteralTargets = map[ArgumentKind]Target{
+	ArgumentKindString: TargetString | TargetEnum,
+	ArgumentKindInt:    TargetInt | TargetReal | TargetEnum,
+	ArgumentKindReal:   TargetReal,
+	ArgumentKindBool:   TargetBool,
+	ArgumentKindNull:   TargetScalar | TargetEnum,
+}
+
+func init() {
+	Register(&Spec{
+		Name:    "id",
+		Targets: TargetInt | TargetString,
+	})
+
+	Register(&Spec{
+		Name:    "default",
+		Targets: TargetScalar | TargetEnum,
+		Arguments: []*ArgumentSpec{
+			{Name: "value", Kind: ArgumentKindLiteral | ArgumentKindFunction, Required: true, Values: []string{FunctionNow, FunctionUuid, FunctionAutoincrement, FunctionUlid}},
+		},
+		Validate: validateDefault,
+	})
+
+	Register(&Spec{
+		Name:    "nullable",
+		Targets: TargetScalar | TargetEnum | TargetModel,
+	})
+
+	Register(&Spec{
+		Name:    "unique",
+		Targets: TargetScalar | TargetEnum,
+	})
+
+	Register(&Spec{
+		Name:    "relation",
+		Targets: TargetModel | TargetModelList,
+		Arguments: []*ArgumentSpec{
+			{Name: "field", Kind: ArgumentKindIdentifier},
+			{Name: "reference", Kind: ArgumentKindIdentifier},
+			{Name: "onDelete", Kind: ArgumentKindIdentifier, Values: referentialActions},
+			{Name: "onUpdate", Kind: ArgumentKindIdentifier, Values: referentialActions},
+			{Name: "name", Kind: ArgumentKindString},
+		},
+		Validate: validateRelation,
+	})
+
+	Register(&Spec{
+		Name:    "map",
+		Targets: TargetScalar | TargetEnum,
+		Arguments: []*ArgumentSpec{
+			{Name: "name", Kind: ArgumentKindString, Required: true},
+		},
+		Validate: validateName,
+	})
+
+	Register(&Spec{
+		Name:    "renamedFrom",
+		Targets: TargetScalar | TargetEnum,
+		Arguments: []*ArgumentSpec{
+			{Name: "name", Kind: ArgumentKindString, Required: true},
+		},
+		Validate: validateName,
+	})
+
+	RegisterModel(&Spec{
+		Name:    "id",
+		Targets: TargetModelDeclaration,
+		Arguments: []*ArgumentSpec{
+			{Name: "fields", Kind: ArgumentKindList, Required: true},
+		},
+		Validate: validateFields,
+	})
+
+	RegisterModel(&Spec{
+		Name:       "unique",
+		Targets:    TargetModelDeclaration,
+		Repeatable: true,
+		Arguments: []*ArgumentSpec{
+			{Name: "fields", Kind: ArgumentKindList, Required: true},
+		},
+		Validate: validateFields,
+	})
+
+	RegisterModel(&Spec{
+		Name:       "index",
+		Targets:    TargetModelDeclaration,
+		Repeatable: true,
+		Arguments: []*ArgumentSpec{
+			{Name: "fields", Kind: ArgumentKindList, Required: true},
+			{Name: "name", Kind: ArgumentKindString},
+		},
+		Validate: validateFields,
+	})
+
+	RegisterModel(&Spec{
+		Name:    "cursor",
+		Targets: TargetModelDeclaration,
+		Arguments: []*ArgumentSpec{
+			{Name: "fields", Kind: ArgumentKindList, Required: true},
+		},
+		Validate: validateFields,
+	})
+
+	RegisterModel(&Spec{
+		Name:    "map",
+		Targets: TargetModelDeclaration,
+		Arguments: []*ArgumentSpec{
+			{Name: "name", Kind: ArgumentKindString, Required: true},
+		},
+		Validate: validateName,
+	})
+}
+
+// validateName requires a non empty table or column name.
+func validateName(dec *ast.Decorator, args Arguments, target Target) *diagnostic.Diagnostic {
+	arg := args["name"]
+	if arg.Value.Value == "" {
+		return diagnostic.Errorf(CodeInvalidValue, argumentToken(arg), "%s%s requires a name", dec.Token.Literal, dec.Name.Identifier)
+	}
+
+	return nil
+}
+
+// validateFields requires a non empty list of field names.
+func validateFields(dec *ast.Decorator, args Arguments, target Target) *diagnostic.Diagnostic {
+	arg := args["fields"]
+	if len(arg.Value.Items) == 0 {
+		return diagnostic.Errorf(CodeInvalidValue, argumentToken(arg), "@@%s requires at least one field", dec.Name.Identifier)
+	}
+
+	for _, item := range arg.Value.Items {
+		if item.Token.Type != lexer.TokenTypeIdent {
+			return diagnostic.Errorf(CodeInvalidValue, item.Token, "%s is not a field name", item)
+		}
+	}
+
+	return nil
+}
+
+// validateRelation requires the foreign key of a relation to a single model.
+// A list field is the other side of a relation or one side of a many-to-many
+// relation, it has no foreign key and only takes a name.
+func validateRelation(dec *ast.Decorator, args Arguments, target Target) *diagnostic.Diagnostic {
+	if target == TargetModelList {
+		for _, name := range []string{"field", "reference", "onDelete", "onUpdate"} {
+			if arg, ok := args[name]; ok {
+				diag := diagnostic.Errorf(CodeUnknownArgument, argumentToken(arg), "argument %s of @relation can not be used on a list", name)
+				return diag.WithHint("@relation on a list only takes name")
+			}
+		}
+
+		return nil
+	}
+
+	for _, name := range []string{"field", "reference"} {
+		if _, ok := args[name]; !ok {
+			return diagnostic.Errorf(CodeMissingArgument, dec.Name.Token, "@relation requires argument %s", name)
+		}
+	}
+
+	return nil
+}
+
+func validateDefault(dec *ast.Decorator, args Arguments, target Target) *diagnostic.Diagnostic {
+	arg := args["value"]
+	kind := KindOf(arg)
+
+	valid := defaultLiteralTargets[kind]
+	if kind == ArgumentKindFunction {
+		valid = defaultFunctionTargets[ValueOf(arg)]
+	}
+
+	if valid&target == 0 {
+		diag := diagnostic.Errorf(CodeInvalidValue, argumentToken(arg), "%s can not be the default of a %s field", arg, target)
+		return diag.WithHint("it can be the default of " + valid.String())
+	}
+
+	return nil
+}
+
+type Id struct {
+	Decorator *ast.Decorator
+}
+
+// Default is either a literal value or a function computing the value.
+type Default struct {
+	Decorator *ast.Decorator
+	Value     *ast.Value
+	Function  string
+}
+
+func (d *Default) IsFunction() bool {
+	return d.Function != ""
+}
+
+type Nullable struct {
+	Decorator *ast.Decorator
+}
+
+type Unique struct {
+	Decorator *ast.Decorator
+}
+
+// Map is the name of the table of a model or of the column of a field.
+type Map struct {
+	Decorator *ast.Decorator
+	Name      string
+}
+
+// RenamedFrom is the previous name of a field, a migration renames its
+// column instead of dropping it.
+type RenamedFrom struct {
+	Decorator *ast.Decorator
+	Name      string
+}
+
+// Relation describes a foreign key, the referential actions are empty if
+// they are not set. On a list field only the name of the relation is set.
+type Relation struct {
+	Decorator *ast.Decorator
+	Field     string
+	Reference string
+	OnDelete  string
+	OnUpdate  string
+	Name      string
+}
+
+// Field holds the typed decorators of a field declaration. Decorators which
+// are not applied are nil.
+type Field struct {
+	Id          *Id
+	Default     *Default
+	Nullable    *Nullable
+	Unique      *Unique
+	Relation    *Relation
+	Map         *Map
+	RenamedFrom *RenamedFrom
+}
+
+// ForField returns the typed decorators of the declaration. Invalid
+// decorators are skipped, they are reported by the analyzer.
+func ForField(decl *ast.Declaration) *Field {
+	field := Field{}
+
+	for _, dec := range decl.Decorators {
+		spec, ok := Lookup(dec.Name.Identifier)
+		if !ok {
+			continue
+		}
+
+		args, diags := bind(spec, dec)
+		if len(diags) > 0 {
+			continue
+		}
+
+		switch spec.Name {
+		case "id":
+			field.Id = &Id{Decorator: dec}
+		case "default":
+			arg, ok := args["value"]
+			if !ok {
+				continue
+			}
+
+			def := Default{Decorator: dec}
+			if arg.Type == ast.ArgumentTypeCallable {
+				def.Function = arg.Callable.Identifier.Identifier
+			} else {
+				def.Value = arg.Value
+			}
+			field.Default = &def
+		case "nullable":
+			field.Nullable = &Nullable{Decorator: dec}
+		case "unique":
+			field.Unique = &Unique{Decorator: dec}
+		case "map":
+			if arg, ok := args["name"]; ok {
+				field.Map = &Map{Decorator: dec, Name: ValueOf(arg)}
+			}
+		case "renamedFrom":
+			if arg, ok := args["name"]; ok {
+				field.RenamedFrom = &RenamedFrom{Decorator: dec, Name: ValueOf(arg)}
+			}
+		case "relation":
+			relation := Relation{Decorator: dec}
+			if arg, ok := args["field"]; ok {
+				relation.Field = ValueOf(arg)
+			}
+			if arg, ok := args["reference"]; ok {
+				relation.Reference = ValueOf(arg)
+			}
+			if arg, ok := args["onDelete"]; ok {
+				relation.OnDelete = ValueOf(arg)
+			}
+			if arg, ok := args["onUpdate"]; ok {
+				relation.OnUpdate = ValueOf(arg)
+			}
+			if arg, ok := args["name"]; ok {
+				relation.Name = ValueOf(arg)
+			}
+			field.Relation = &relation
+		}
+	}
+
+	return &field
+}
+
+// CompositeId is a primary key over several fields of a model.
+type CompositeId struct {
+	Decorator *ast.Decorator
+	Fields    []string
+}
+
+// CompositeUnique is a unique constraint over several fields of a model.
+type CompositeUnique struct {
+	Decorator *ast.Decorator
+	Fields    []string
+}
+
+// Index is an index over fields of a model, Name is empty if the index is
+// not named.
+type Index struct {
+	Decorator *ast.Decorator
+	Fields    []string
+	Name      string
+}
+
+// Cursor lists the fields the pages of a model are sorted by.
+type Cursor struct {
+	Decorator *ast.Decorator
+	Fields    []string
+}
+
+// Model holds the typed @@ decorators of a model declaration.
+type Model struct {
+	Id     *CompositeId
+	Unique []*CompositeUnique
+	Index  []*Index
+	Cursor *Cursor
+	Map    *Map
+}
+
+// ForModel returns the typed @@ decorators of the model. Invalid decorators
+// are skipped, they are reported by the analyzer.
+func ForModel(model *ast.Model) *Model {
+	result := Model{}
+
+	for _, dec := range model.Decorators {
+		spec, ok := LookupModel(dec.Name.Identifier)
+		if !ok {
+			continue
+		}
+
+		args, diags := bind(spec, dec)
+		if len(diags) > 0 {
+			continue
+		}
+
+		if spec.Name == "map" {
+			if arg, ok := args["name"]; ok {
+				result.Map = &Map{Decorator: dec, Name: ValueOf(arg)}
+			}
+			continue
+		}
+
+		arg, ok := args["fields"]
+		if !ok || KindOf(arg) != ArgumentKindList {
+			continue
+		}
+
+		fields := []string{}
+		for _, item := range arg.Value.Items {
+			fields = append(fields, item.Value)
+		}
+
+		switch spec.Name {
+		case "id":
+			result.Id = &CompositeId{Decorator: dec, Fields: fields}
+		case "unique":
+			result.Unique = append(result.Unique, &CompositeUnique{Decorator: dec, Fields: fields})
+		case "index":
+			index := Index{Decorator: dec, Fields: fields}
+			if arg, ok := args["name"]; ok {
+				index.Name = ValueOf(arg)
+			}
+			result.Index = append(result.Index, &index)
+		case "cursor":
+			result.Cursor = &Cursor{Decorator: dec, Fields: fields}
+		}
+	}
+
+	return &result
+}
@@ -0,0 +1,134 @@
+package decorator
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+)
+
+// Naming strategies of the naming option of the db block. They derive the
+// names of tables and columns from the names of models and fields, names
+// set with @@map and @map are kept as they are.
+const (
+	NamingPreserve    = "preserve"
+	NamingSnake       = "snake"
+	NamingSnakePlural = "snake_plural"
+)
+
+var Namings = []string{NamingPreserve, NamingSnake, NamingSnakePlural}
+
+// Naming turns the name of a model into the name of its table and the name
+// of a field into the name of its column.
+type Naming struct {
+	Table  func(string) string
+	Column func(string) string
+}
+
+func preserve(name string) string {
+	return name
+}
+
+var namings = map[string]*Naming{
+	NamingPreserve: {Table: preserve, Column: preserve},
+	NamingSnake:    {Table: snakeCase, Column: snakeCase},
+	NamingSnakePlural: {
+		Table:  func(name string) string { return plural(snakeCase(name)) },
+		Column: snakeCase,
+	},
+}
+
+// LookupNaming returns the naming strategy with the name.
+func LookupNaming(name string) (*Naming, bool) {
+	naming, ok := namings[name]
+	return naming, ok
+}
+
+// NamingOf returns the naming strategy set in the db block of the schema,
+// names are preserved if none or an unknown one is set.
+func NamingOf(schema *ast.Ast) *Naming {
+	if schema == nil {
+		return namings[NamingPreserve]
+	}
+
+	item, ok := schema.ConfigItem("db", "naming")
+	if !ok {
+		return namings[NamingPreserve]
+	}
+
+	naming, ok := LookupNaming(item.Value.Value)
+	if !ok {
+		return namings[NamingPreserve]
+	}
+
+	return naming
+}
+
+// TableName returns the name of the table of the model, the name set with
+// @@map or else the name of the model after the naming of the schema.
+func TableName(schema *ast.Ast, model *ast.Model) string {
+	mapped := ForModel(model).Map
+	if mapped != nil {
+		return mapped.Name
+	}
+
+	return NamingOf(schema).Table(model.Name.Identifier)
+}
+
+// ColumnName returns the name of the column of the field, the name set with
+// @map or else the name of the field after the naming of the schema.
+func ColumnName(schema *ast.Ast, item *ast.Declaration) string {
+	mapped := ForField(item).Map
+	if mapped != nil {
+		return mapped.Name
+	}
+
+	return NamingOf(schema).Column(item.Identifier.Identifier)
+}
+
+// snakeCase turns a camel case name into lower case words separated by
+// underscores, e.g. createdAt into created_at and HTTPServer into
+// http_server.
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var sb strings.Builder
+	for i, ch := range runes {
+		if unicode.IsUpper(ch) {
+			if i > 0 && runes[i-1] != '_' && isWordStart(runes, i) {
+				sb.WriteRune('_')
+			}
+			ch = unicode.ToLower(ch)
+		}
+
+		sb.WriteRune(ch)
+	}
+
+	return sb.String()
+}
+
+// isWordStart tells whether the upper case rune at i starts a word, i.e.
+// follows a lower case rune or a digit, or ends an acronym like HTTP in
+// HTTPServer.
+func isWordStart(runes []rune, i int) bool {
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+
+	return unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// plural returns the english plural of the last word of a name, irregular
+// nouns are not handled.
+func plural(name string) string {
+	switch {
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+
+	return name + "s"
+}
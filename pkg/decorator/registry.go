@@ -0,0 +1,399 @@
+package decorator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+const (
+	CodeUnknownDecorator    = "D001"
+	CodeInvalidTarget       = "D002"
+	CodeUnknownArgument     = "D003"
+	CodeInvalidArgumentKind = "D004"
+	CodeMissingArgument     = "D005"
+	CodeDuplicateArgument   = "D006"
+	CodeInvalidValue        = "D007"
+	CodeDuplicateDecorator  = "D008"
+)
+
+var registry = map[string]*Spec{}
+
+// modelRegistry holds the @@ decorators, they share their names with field
+// decorators, e.g. @unique and @@unique.
+var modelRegistry = map[string]*Spec{}
+
+// Target is the kind of declaration a decorator is applied to.
+type Target int
+
+const (
+	TargetInt Target = 1 << iota
+	TargetReal
+	TargetBool
+	TargetString
+	TargetDateTime
+	TargetEnum
+	TargetModel
+	TargetModelList
+	TargetModelDeclaration
+
+	TargetScalar = TargetInt | TargetReal | TargetBool | TargetString | TargetDateTime
+)
+
+var targetNames = []struct {
+	target Target
+	name   string
+}{
+	{TargetInt, "int"},
+	{TargetReal, "real"},
+	{TargetBool, "bool"},
+	{TargetString, "string"},
+	{TargetDateTime, "DateTime"},
+	{TargetEnum, "enum"},
+	{TargetModel, "model"},
+	{TargetModelList, "model list"},
+	{TargetModelDeclaration, "model declaration"},
+}
+
+func (t Target) String() string {
+	names := []string{}
+	for _, item := range targetNames {
+		if t&item.target != 0 {
+			names = append(names, item.name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// ArgumentKind is the kind of value an argument accepts.
+type ArgumentKind int
+
+const (
+	ArgumentKindString ArgumentKind = 1 << iota
+	ArgumentKindInt
+	ArgumentKindBool
+	ArgumentKindIdentifier
+	ArgumentKindFunction
+	ArgumentKindList
+	ArgumentKindReal
+	ArgumentKindNull
+
+	ArgumentKindLiteral = ArgumentKindString | ArgumentKindInt | ArgumentKindReal | ArgumentKindBool | ArgumentKindNull
+)
+
+var argumentKindNames = []struct {
+	kind ArgumentKind
+	name string
+}{
+	{ArgumentKindString, "string"},
+	{ArgumentKindInt, "int"},
+	{ArgumentKindReal, "real"},
+	{ArgumentKindBool, "bool"},
+	{ArgumentKindIdentifier, "identifier"},
+	{ArgumentKindFunction, "function"},
+	{ArgumentKindList, "list"},
+	{ArgumentKindNull, "null"},
+}
+
+func (k ArgumentKind) String() string {
+	names := []string{}
+	for _, item := range argumentKindNames {
+		if k&item.kind != 0 {
+			names = append(names, item.name)
+		}
+	}
+
+	return strings.Join(names, " or ")
+}
+
+// KindOf returns the kind of the value passed as argument.
+func KindOf(arg *ast.Argument) ArgumentKind {
+	if arg.Type == ast.ArgumentTypeCallable {
+		return ArgumentKindFunction
+	}
+
+	if arg.Value.Type == ast.ValueTypeList {
+		return ArgumentKindList
+	}
+
+	switch arg.Value.Type {
+	case ast.ValueTypeInt:
+		return ArgumentKindInt
+	case ast.ValueTypeReal:
+		return ArgumentKindReal
+	case ast.ValueTypeBool:
+		return ArgumentKindBool
+	case ast.ValueTypeNull:
+		return ArgumentKindNull
+	}
+
+	// identifiers are string values too
+	if arg.Value.Token.Type == lexer.TokenTypeString {
+		return ArgumentKindString
+	}
+
+	return ArgumentKindIdentifier
+}
+
+// ArgumentSpec describes one argument of a decorator. Arguments may be
+// passed by name or by position in the order they are declared in.
+type ArgumentSpec struct {
+	Name     string
+	Kind     ArgumentKind
+	Required bool
+	// Values restricts identifiers and functions to a set of names.
+	Values []string
+}
+
+// Spec describes a decorator, where it can be used and which arguments it
+// takes.
+type Spec struct {
+	Name      string
+	Targets   Target
+	Arguments []*ArgumentSpec
+	// Repeatable decorators may be applied more than once to a model.
+	Repeatable bool
+	// Validate checks constraints between the arguments and the target that
+	// can not be described by the argument specs.
+	Validate func(dec *ast.Decorator, args Arguments, target Target) *diagnostic.Diagnostic
+}
+
+// Arguments are the arguments of a decorator call by their spec name.
+type Arguments map[string]*ast.Argument
+
+func Register(spec *Spec) {
+	_, ok := registry[spec.Name]
+	if ok {
+		panic(fmt.Sprintf("decorator %s already exists", spec.Name))
+	}
+
+	registry[spec.Name] = spec
+}
+
+func Lookup(name string) (*Spec, bool) {
+	spec, ok := registry[name]
+	return spec, ok
+}
+
+// RegisterModel registers a @@ decorator applying to a whole model.
+func RegisterModel(spec *Spec) {
+	_, ok := modelRegistry[spec.Name]
+	if ok {
+		panic(fmt.Sprintf("decorator @@%s already exists", spec.Name))
+	}
+
+	modelRegistry[spec.Name] = spec
+}
+
+func LookupModel(name string) (*Spec, bool) {
+	spec, ok := modelRegistry[name]
+	return spec, ok
+}
+
+// lookup finds the spec of a decorator call in the registry matching its
+// @ or @@ prefix.
+func lookup(dec *ast.Decorator) (*Spec, bool) {
+	if dec.Token.Type == lexer.TokenTypeModelDecorator {
+		return LookupModel(dec.Name.Identifier)
+	}
+
+	return Lookup(dec.Name.Identifier)
+}
+
+// Names returns the names of every registered decorator in alphabetical
+// order.
+func Names() []string {
+	return sortedNames(registry)
+}
+
+// ModelNames returns the names of every registered @@ decorator in
+// alphabetical order.
+func ModelNames() []string {
+	return sortedNames(modelRegistry)
+}
+
+func sortedNames(specs map[string]*Spec) []string {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Check validates a decorator against its spec for the given target.
+func Check(dec *ast.Decorator, target Target) diagnostic.Diagnostics {
+	prefix := dec.Token.Literal
+
+	spec, ok := lookup(dec)
+	if !ok {
+		names := Names()
+		if dec.Token.Type == lexer.TokenTypeModelDecorator {
+			names = ModelNames()
+		}
+
+		diag := diagnostic.Errorf(CodeUnknownDecorator, dec.Name.Token, "unknown decorator %s%s", prefix, dec.Name.Identifier)
+		return diagnostic.Diagnostics{diag.WithHint("known decorators are " + prefix + strings.Join(names, ", "+prefix))}
+	}
+
+	if spec.Targets&target == 0 {
+		diag := diagnostic.Errorf(CodeInvalidTarget, dec.Name.Token, "%s%s can not be applied to %s", prefix, spec.Name, target)
+		return diagnostic.Diagnostics{diag.WithHint(prefix + spec.Name + " can be applied to " + spec.Targets.String())}
+	}
+
+	args, diags := bind(spec, dec)
+	if len(diags) > 0 {
+		return diags
+	}
+
+	for _, argSpec := range spec.Arguments {
+		arg, ok := args[argSpec.Name]
+		if !ok {
+			if argSpec.Required {
+				diags = append(diags, diagnostic.Errorf(CodeMissingArgument, dec.Name.Token, "@%s requires argument %s", spec.Name, argSpec.Name))
+			}
+			continue
+		}
+
+		diag := checkArgument(spec, argSpec, arg)
+		if diag != nil {
+			diags = append(diags, diag)
+		}
+	}
+
+	if len(diags) == 0 && spec.Validate != nil {
+		diag := spec.Validate(dec, args, target)
+		if diag != nil {
+			diags = append(diags, diag)
+		}
+	}
+
+	return diags
+}
+
+// Bind maps the arguments of a decorator call to the names of its argument
+// specs.
+func Bind(dec *ast.Decorator) (Arguments, diagnostic.Diagnostics) {
+	spec, ok := lookup(dec)
+	if !ok {
+		return Arguments{}, nil
+	}
+
+	return bind(spec, dec)
+}
+
+// bind maps the arguments of a decorator call to the argument specs.
+func bind(spec *Spec, dec *ast.Decorator) (Arguments, diagnostic.Diagnostics) {
+	args := Arguments{}
+	diags := diagnostic.Diagnostics{}
+
+	if dec.Callable == nil {
+		return args, diags
+	}
+
+	for idx, arg := range dec.Callable.Arguments {
+		var argSpec *ArgumentSpec
+
+		if arg.Name != nil {
+			argSpec = spec.argument(arg.Name.Identifier)
+			if argSpec == nil {
+				diag := diagnostic.Errorf(CodeUnknownArgument, arg.Name.Token, "@%s has no argument %s", spec.Name, arg.Name.Identifier)
+				diags = append(diags, diag.WithHint(spec.argumentsHint()))
+				continue
+			}
+		} else {
+			if idx >= len(spec.Arguments) {
+				diag := diagnostic.Errorf(CodeUnknownArgument, argumentToken(arg), "too many arguments for @%s", spec.Name)
+				diags = append(diags, diag.WithHint(spec.argumentsHint()))
+				continue
+			}
+
+			argSpec = spec.Arguments[idx]
+		}
+
+		if _, ok := args[argSpec.Name]; ok {
+			diags = append(diags, diagnostic.Errorf(CodeDuplicateArgument, argumentToken(arg), "argument %s of @%s is given more than once", argSpec.Name, spec.Name))
+			continue
+		}
+
+		args[argSpec.Name] = arg
+	}
+
+	return args, diags
+}
+
+func checkArgument(spec *Spec, argSpec *ArgumentSpec, arg *ast.Argument) *diagnostic.Diagnostic {
+	kind := KindOf(arg)
+	if argSpec.Kind&kind == 0 {
+		return diagnostic.Errorf(CodeInvalidArgumentKind, argumentToken(arg), "argument %s of @%s must be %s but found %s", argSpec.Name, spec.Name, argSpec.Kind, kind)
+	}
+
+	if kind == ArgumentKindFunction && len(arg.Callable.Arguments) > 0 {
+		return diagnostic.Errorf(CodeInvalidValue, argumentToken(arg), "%s() takes no arguments", arg.Callable.Identifier.Identifier)
+	}
+
+	if len(argSpec.Values) > 0 && (kind == ArgumentKindIdentifier || kind == ArgumentKindFunction) {
+		name := ValueOf(arg)
+		for _, value := range argSpec.Values {
+			if value == name {
+				return nil
+			}
+		}
+
+		diag := diagnostic.Errorf(CodeInvalidValue, argumentToken(arg), "invalid value %s for argument %s of @%s", name, argSpec.Name, spec.Name)
+		return diag.WithHint("valid values are " + strings.Join(argSpec.Values, ", "))
+	}
+
+	return nil
+}
+
+func (s *Spec) argument(name string) *ArgumentSpec {
+	for _, arg := range s.Arguments {
+		if arg.Name == name {
+			return arg
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) argumentsHint() string {
+	if len(s.Arguments) == 0 {
+		return "@" + s.Name + " takes no arguments"
+	}
+
+	names := make([]string, len(s.Arguments))
+	for i, arg := range s.Arguments {
+		names[i] = arg.Name
+	}
+
+	return "@" + s.Name + " takes " + strings.Join(names, ", ")
+}
+
+// ValueOf returns the raw value of an argument, for functions the name of
+// the function.
+func ValueOf(arg *ast.Argument) string {
+	if arg.Type == ast.ArgumentTypeCallable {
+		return arg.Callable.Identifier.Identifier
+	}
+
+	return arg.Value.Value
+}
+
+func argumentToken(arg *ast.Argument) *lexer.Token {
+	if arg.Name != nil {
+		return arg.Name.Token
+	}
+
+	if arg.Type == ast.ArgumentTypeCallable {
+		return arg.Callable.Identifier.Token
+	}
+
+	return arg.Value.Token
+}
@@ -0,0 +1,183 @@
+package diagnostic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+	SeverityHint
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "info",
+	SeverityHint:    "hint",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// Position is a zero based location in a source file.
+type Position struct {
+	Row int
+	Col int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Row+1, p.Col+1)
+}
+
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	Start    Position
+	End      Position
+	Code     string
+	Message  string
+	Hint     string
+}
+
+func NewDiagnostic(severity Severity, code string, start Position, end Position, message string) *Diagnostic {
+	d := Diagnostic{
+		Severity: severity,
+		Start:    start,
+		End:      end,
+		Code:     code,
+		Message:  message,
+	}
+
+	return &d
+}
+
+// Errorf creates an error diagnostic spanning the given token, in the file
+// of the token.
+func Errorf(code string, token *lexer.Token, format string, args ...any) *Diagnostic {
+	d := NewDiagnostic(SeverityError, code, Start(token), End(token), fmt.Sprintf(format, args...))
+	d.File = token.File
+
+	return d
+}
+
+// Warningf creates a warning diagnostic spanning the given token, in the
+// file of the token.
+func Warningf(code string, token *lexer.Token, format string, args ...any) *Diagnostic {
+	d := NewDiagnostic(SeverityWarning, code, Start(token), End(token), fmt.Sprintf(format, args...))
+	d.File = token.File
+
+	return d
+}
+
+// Start returns the position of the first character of the token.
+func Start(token *lexer.Token) Position {
+	return Position{Row: token.Row, Col: token.Col}
+}
+
+// Location returns the file and position of the token, e.g. to point at a
+// previous declaration in a hint.
+func Location(token *lexer.Token) string {
+	if token.File == "" {
+		return Start(token).String()
+	}
+
+	return token.File + ":" + Start(token).String()
+}
+
+// End returns the position just after the last character of the token.
+func End(token *lexer.Token) Position {
+	text := token.Literal
+	if token.Raw != "" {
+		text = token.Raw
+	} else if token.Type == lexer.TokenTypeString {
+		text = strconv.Quote(text)
+	}
+
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		rows := strings.Count(text, "\n")
+		return Position{Row: token.Row + rows, Col: utf8.RuneCountInString(text[i+1:])}
+	}
+
+	width := utf8.RuneCountInString(text)
+	if width == 0 {
+		width = 1
+	}
+
+	return Position{Row: token.Row, Col: token.Col + width}
+}
+
+func (d *Diagnostic) WithHint(hint string) *Diagnostic {
+	d.Hint = hint
+	return d
+}
+
+func (d *Diagnostic) Error() string {
+	var sb strings.Builder
+
+	if d.File != "" {
+		sb.WriteString(d.File)
+		sb.WriteString(":")
+	}
+	sb.WriteString(d.Start.String())
+	sb.WriteString(": ")
+	sb.WriteString(d.Severity.String())
+	if d.Code != "" {
+		sb.WriteString("[")
+		sb.WriteString(d.Code)
+		sb.WriteString("]")
+	}
+	sb.WriteString(": ")
+	sb.WriteString(d.Message)
+
+	return sb.String()
+}
+
+// Diagnostics is a list of diagnostics usable as a single error.
+type Diagnostics []*Diagnostic
+
+func (d Diagnostics) Error() string {
+	lines := make([]string, len(d))
+	for i, diag := range d {
+		lines[i] = diag.Error()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns the list as an error if it contains at least one error
+// diagnostic and nil otherwise.
+func (d Diagnostics) Err() error {
+	if !d.HasErrors() {
+		return nil
+	}
+
+	return d
+}
+
+func (d Diagnostics) SetFile(file string) {
+	for _, diag := range d {
+		if diag.File == "" {
+			diag.File = file
+		}
+	}
+}
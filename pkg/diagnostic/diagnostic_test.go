@@ -0,0 +1,50 @@
+package diagnostic_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+func TestRender(t *testing.T) {
+	source := "model User {\n  id\tstrin\n}\n"
+
+	token := lexer.NewToken(lexer.TokenTypeIdent, "strin", 1, 5)
+	diag := diagnostic.Errorf("P001", token, "expected type but found %s", token.Literal)
+	diag.File = "project.gophoria"
+	diag.WithHint("did you mean string?")
+
+	expected := "error[P001]: expected type but found strin\n" +
+		" --> project.gophoria:2:6\n" +
+		"  |\n" +
+		"2 |   id\tstrin\n" +
+		"  |     \t^^^^^\n" +
+		"  = hint: did you mean string?\n"
+
+	var buffer bytes.Buffer
+	diag.Render(&buffer, source)
+
+	if buffer.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, buffer.String())
+	}
+
+	if diag.Error() != "project.gophoria:2:6: error[P001]: expected type but found strin" {
+		t.Fatalf("unexpected error string %s", diag.Error())
+	}
+}
+
+func TestDiagnosticsErr(t *testing.T) {
+	token := lexer.NewToken(lexer.TokenTypeIdent, "x", 0, 0)
+
+	diags := diagnostic.Diagnostics{diagnostic.Warningf("W001", token, "warning")}
+	if diags.Err() != nil {
+		t.Fatalf("expected no error for warnings only")
+	}
+
+	diags = append(diags, diagnostic.Errorf("E001", token, "error"))
+	if diags.Err() == nil {
+		t.Fatalf("expected error")
+	}
+}
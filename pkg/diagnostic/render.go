@@ -0,0 +1,82 @@
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Render writes the diagnostic followed by the offending source line with
+// the reported range underlined by carets.
+func (d *Diagnostic) Render(writer io.Writer, source string) {
+	fmt.Fprintf(writer, "%s", d.Severity)
+	if d.Code != "" {
+		fmt.Fprintf(writer, "[%s]", d.Code)
+	}
+	fmt.Fprintf(writer, ": %s\n", d.Message)
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Start.Row+1)))
+
+	location := d.Start.String()
+	if d.File != "" {
+		location = d.File + ":" + location
+	}
+	fmt.Fprintf(writer, "%s--> %s\n", gutter, location)
+
+	lines := strings.Split(source, "\n")
+	if d.Start.Row >= 0 && d.Start.Row < len(lines) {
+		line := strings.TrimRight(lines[d.Start.Row], "\r")
+
+		width := 1
+		if d.End.Row == d.Start.Row && d.End.Col > d.Start.Col {
+			width = d.End.Col - d.Start.Col
+		} else if d.End.Row > d.Start.Row {
+			width = max(utf8.RuneCountInString(line)-d.Start.Col, 1)
+		}
+
+		fmt.Fprintf(writer, "%s |\n", gutter)
+		fmt.Fprintf(writer, "%d | %s\n", d.Start.Row+1, line)
+		fmt.Fprintf(writer, "%s | %s%s\n", gutter, padding(line, d.Start.Col), strings.Repeat("^", width))
+	}
+
+	if d.Hint != "" {
+		fmt.Fprintf(writer, "%s = hint: %s\n", gutter, d.Hint)
+	}
+}
+
+// Render writes every diagnostic in the list, looking up the source of
+// each one by its file name.
+func (d Diagnostics) Render(writer io.Writer, source func(file string) string) {
+	for i, diag := range d {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+
+		diag.Render(writer, source(diag.File))
+	}
+}
+
+// padding keeps tabs of the source line so the carets stay aligned.
+func padding(line string, col int) string {
+	var sb strings.Builder
+
+	for i, ch := range []rune(line) {
+		if i >= col {
+			break
+		}
+
+		if ch == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+
+	for i := utf8.RuneCountInString(line); i < col; i++ {
+		sb.WriteRune(' ')
+	}
+
+	return sb.String()
+}
@@ -14,7 +14,7 @@ type DaisyUiGenerator struct {
 }
 
 func init() {
-	RegisterGenerator("daisyui", NewDaisyUiGenerator())
+	RegisterGenerator("daisyui", func() Generator { return NewDaisyUiGenerator() })
 }
 
 func NewDaisyUiGenerator() *DaisyUiGenerator {
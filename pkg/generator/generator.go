@@ -4,9 +4,10 @@ import (
 	"fmt"
 
 	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/migration"
 )
 
-var generators = map[string]Generator{}
+var generators = map[string]func() Generator{}
 
 type GeneratorConfig struct {
 	Override   bool
@@ -18,20 +19,35 @@ type Generator interface {
 	Generate(ast *ast.Ast, cfg *GeneratorConfig, name string) error
 }
 
+// MigrationGenerator is implemented by the database generators which write
+// incremental migrations, a pair of <name>.up.sql and <name>.down.sql files.
+type MigrationGenerator interface {
+	GenerateMigration(m *migration.Migration, cfg *GeneratorConfig, name string) error
+}
+
+// FieldGenerator is implemented by the generators which can show the code
+// they write for a single field, e.g. the column definition of CREATE TABLE.
+// It is empty if the generator writes nothing for the field.
+type FieldGenerator interface {
+	GenerateField(ast *ast.Ast, item *ast.Declaration) (string, error)
+}
+
+// GetGenerator returns a new generator of the name, generators keep the
+// config and schema of a run so they are not shared.
 func GetGenerator(name string) (Generator, error) {
-	gen, ok := generators[name]
+	newGen, ok := generators[name]
 	if !ok {
 		return nil, fmt.Errorf("generator %s not found", name)
 	}
 
-	return gen, nil
+	return newGen(), nil
 }
 
-func RegisterGenerator(name string, gen Generator) {
+func RegisterGenerator(name string, newGen func() Generator) {
 	_, ok := generators[name]
 	if ok {
 		panic(fmt.Sprintf("generator %s already exists", name))
 	}
 
-	generators[name] = gen
+	generators[name] = newGen
 }
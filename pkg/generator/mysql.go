@@ -5,20 +5,26 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 
+	"github.com/gophoria/gophoria/pkg/analyzer"
 	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/migration"
 )
 
 var mysqlTypes = map[ast.VariableType][]byte{
 	ast.VariableTypeInt:      []byte("INT"),
-	ast.VariableTypeReal:     []byte("DECIMAL"),
-	ast.VariableTypeBool:     []byte("BOOL"),
-	ast.VariableTypeString:   []byte("VARCHAR(255"),
-	ast.VariableTypeDateTime: []byte("DATETIME"),
+	ast.VariableTypeReal:     []byte("DOUBLE"),
+	ast.VariableTypeBool:     []byte("BOOLEAN"),
+	ast.VariableTypeString:   []byte("VARCHAR(255)"),
+	ast.VariableTypeDateTime: []byte("DATETIME(6)"),
 }
 
+const mysqlTableOptions = "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"
+
 func init() {
-	RegisterGenerator("mysql", NewSqlite3Generator())
+	RegisterGenerator("mysql", func() Generator { return NewMysqlGenerator() })
 }
 
 type MysqlGenerator struct {
@@ -42,13 +48,20 @@ func (g *MysqlGenerator) GenerateAll(ast *ast.Ast, cfg *GeneratorConfig) error {
 		return err
 	}
 
-	for idx, model := range ast.Models {
+	for idx, model := range migration.SortModels(ast.Models) {
 		err := g.generateModel(model, idx)
 		if err != nil {
 			return err
 		}
 	}
 
+	for idx, table := range analyzer.JoinTables(ast) {
+		err := g.generateJoinTable(table, len(ast.Models)+idx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -63,7 +76,7 @@ func (g *MysqlGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name strin
 
 	isExist := false
 
-	for idx, model := range ast.Models {
+	for idx, model := range migration.SortModels(ast.Models) {
 		if model.Name.Identifier == name {
 			isExist = true
 
@@ -74,14 +87,30 @@ func (g *MysqlGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name strin
 		}
 	}
 
+	for idx, table := range analyzer.JoinTables(ast) {
+		if table.Name == name {
+			isExist = true
+
+			err := g.generateJoinTable(table, len(ast.Models)+idx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if !isExist {
-		return fmt.Errorf("model %s not found", name)
+		return fmt.Errorf("model or join table %s not found", name)
 	}
 
 	return nil
 }
 
 func (g *MysqlGenerator) generateModel(model *ast.Model, idx int) error {
+	sql, err := g.createTable(model)
+	if err != nil {
+		return err
+	}
+
 	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, model.Name.Identifier)))
 	if err != nil {
 		return err
@@ -90,50 +119,349 @@ func (g *MysqlGenerator) generateModel(model *ast.Model, idx int) error {
 
 	g.writer = f
 
-	g.writer.Write([]byte("CREATE TABLE IF NOT EXISTS "))
-	g.writer.Write([]byte(model.Name.Token.Literal))
-	g.writer.Write([]byte(" (\n"))
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
 
-	for idx, item := range model.Items {
-		err := g.generateItem(item, idx == len(model.Items)-1)
+	return nil
+}
+
+// createTable returns the CREATE TABLE statement of a model. Constraints
+// are named like postgres names them, mysql would number foreign keys and
+// name unique keys after their first column.
+func (g *MysqlGenerator) createTable(model *ast.Model) (string, error) {
+	lines := []string{}
+	for _, item := range model.Items {
+		line, err := g.generateItem(item)
 		if err != nil {
-			return err
+			return "", err
 		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	constraints, indexes := tableConstraints(g.ast, model)
+	for _, constraint := range constraints {
+		lines = append(lines, "  "+g.constraint(constraint))
+	}
+
+	for _, index := range indexes {
+		lines = append(lines, fmt.Sprintf("  INDEX %s (%s)", g.quote(index.Name), columnList(index.Columns, g.quote)))
 	}
 
-	g.writer.Write([]byte(");\n\n"))
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(decorator.TableName(g.ast, model)))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n) ")
+	sb.WriteString(mysqlTableOptions)
+	sb.WriteString(";\n")
+
+	return sb.String(), nil
+}
+
+// constraint returns the definition of a named constraint, mysql ignores
+// the name of a primary key.
+func (g *MysqlGenerator) constraint(constraint *migration.ConstraintChange) string {
+	columns := columnList(constraint.Columns, g.quote)
+
+	switch constraint.Kind {
+	case migration.ConstraintPrimaryKey:
+		return fmt.Sprintf("PRIMARY KEY (%s)", columns)
+	case migration.ConstraintForeignKey:
+		return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)%s", g.quote(constraint.Name), columns, g.quote(constraint.ReferenceTable), g.quote(constraint.ReferenceColumn), foreignKeyActions(constraint.Relation))
+	}
+
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", g.quote(constraint.Name), columns)
+}
+
+// generateJoinTable creates the table of a many-to-many relation, a row links
+// one row of each model and is removed with either of them.
+func (g *MysqlGenerator) generateJoinTable(table *analyzer.JoinTable, idx int) error {
+	sql, err := g.createJoinTable(table)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, table.Name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	g.writer = f
+
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
 
 	return nil
 }
 
-func (g *MysqlGenerator) generateItem(item *ast.Declaration, isLast bool) error {
-	decType, ok := g.typeToSqliteType(item.DeclarationType)
-	if !ok {
-		if g.isTypeModel(item.DeclarationType) {
-			return nil
+func (g *MysqlGenerator) createJoinTable(table *analyzer.JoinTable) (string, error) {
+	lines := []string{}
+	keys := []string{}
+	for _, end := range []*analyzer.RelationEnd{table.A, table.B} {
+		id, ok := idField(end.Model)
+		if !ok {
+			return "", fmt.Errorf("model %s has no @id field", end.Model.Name.Identifier)
+		}
+
+		decType, ok := g.typeToMysqlType(id.DeclarationType)
+		if !ok {
+			return "", fmt.Errorf("invalid type %s", id.DeclarationType.Name)
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s %s NOT NULL", g.quote(end.Column), decType))
+		keys = append(keys, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE CASCADE", g.quote(end.Column), g.quote(decorator.TableName(g.ast, end.Model)), g.quote(decorator.ColumnName(g.ast, id))))
+	}
+
+	lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s, %s)", g.quote(table.A.Column), g.quote(table.B.Column)))
+	lines = append(lines, keys...)
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(table.Name))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n) ")
+	sb.WriteString(mysqlTableOptions)
+	sb.WriteString(";\n")
+
+	return sb.String(), nil
+}
+
+func (g *MysqlGenerator) GenerateMigration(m *migration.Migration, cfg *GeneratorConfig, name string) error {
+	g.cfg = cfg
+
+	up, err := g.migrate(m.Changes)
+	if err != nil {
+		return err
+	}
+
+	down, err := g.migrate(m.Down())
+	if err != nil {
+		return err
+	}
+
+	return writeMigration(cfg, name, up, down)
+}
+
+// migrate renders the changes of a migration. Enums are column types in
+// mysql, a changed enum alters the columns using it.
+func (g *MysqlGenerator) migrate(changes []*migration.Change) (string, error) {
+	statements := []string{}
+
+	for _, change := range changes {
+		g.ast = change.To
+
+		sql := ""
+		var err error
+
+		switch change.Type {
+		case migration.ChangeCreateTable:
+			sql, err = g.createTable(change.Model)
+		case migration.ChangeDropTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)))
+		case migration.ChangeRenameTable:
+			sql = fmt.Sprintf("RENAME TABLE %s TO %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)), g.quote(decorator.TableName(change.To, change.Model)))
+		case migration.ChangeAlterTable:
+			sql, err = g.alterTable(change)
+		case migration.ChangeCreateJoinTable:
+			sql, err = g.createJoinTable(change.JoinTable)
+		case migration.ChangeDropJoinTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(change.PrevJoinTable.Name))
+		}
+
+		if err != nil {
+			return "", err
+		}
+		if sql != "" {
+			statements = append(statements, sql)
+		}
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+func (g *MysqlGenerator) alterTable(change *migration.Change) (string, error) {
+	table := g.quote(change.Table)
+	statements := []string{}
+
+	for _, constraint := range change.Constraints {
+		if constraint.Type != migration.ConstraintDrop {
+			continue
+		}
+
+		switch constraint.Kind {
+		case migration.ConstraintForeignKey:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;\n", table, g.quote(constraint.Name)))
+		case migration.ConstraintPrimaryKey:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY;\n", table))
+		default:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;\n", table, g.quote(constraint.Name)))
+		}
+	}
+
+	for _, column := range change.Columns {
+		switch column.Type {
+		case migration.ColumnAdd:
+			line, err := g.generateItem(column.Field)
+			if err != nil {
+				return "", err
+			}
+
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", table, strings.TrimSpace(line)))
+		case migration.ColumnDrop:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", table, g.quote(decorator.ColumnName(change.From, column.PrevField))))
+		case migration.ColumnRename:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;\n", table, g.quote(decorator.ColumnName(change.From, column.PrevField)), g.quote(decorator.ColumnName(g.ast, column.Field))))
+		case migration.ColumnAlter:
+			alter, err := g.alterColumn(change, column)
+			if err != nil {
+				return "", err
+			}
+
+			statements = append(statements, alter...)
+		}
+	}
+
+	for _, constraint := range change.Constraints {
+		if constraint.Type != migration.ConstraintAdd {
+			continue
+		}
+
+		if constraint.Kind == migration.ConstraintIndex {
+			statements = append(statements, fmt.Sprintf("CREATE INDEX %s ON %s (%s);\n", g.quote(constraint.Name), table, columnList(constraint.Columns, g.quote)))
 		} else {
-			return fmt.Errorf("invalid type %s", item.DeclarationType.Name)
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s;\n", table, g.constraint(constraint)))
 		}
 	}
 
-	g.writer.Write([]byte("  "))
-	g.writer.Write([]byte(item.Identifier.Identifier))
-	g.writer.Write([]byte(" "))
-	g.writer.Write(decType)
+	return strings.Join(statements, ""), nil
+}
+
+// alterColumn redefines a column with MODIFY COLUMN, its unique and primary
+// keys are indexes which are added and dropped on their own.
+func (g *MysqlGenerator) alterColumn(change *migration.Change, column *migration.ColumnChange) ([]string, error) {
+	table := g.quote(change.Table)
+	name := g.quote(decorator.ColumnName(g.ast, column.Field))
 
-	if _, ok := g.getDecorator("id", item.Decorators); ok {
-		g.writer.Write([]byte(" PRIMARY KEY"))
+	definition, err := g.columnDefinition(column.Field)
+	if err != nil {
+		return nil, err
 	}
 
-	if !isLast {
-		g.writer.Write([]byte(","))
+	g.ast = change.From
+	prevDefinition, err := g.columnDefinition(column.PrevField)
+	g.ast = change.To
+	if err != nil {
+		return nil, err
 	}
-	g.writer.Write([]byte("\n"))
 
-	return nil
+	decorators := decorator.ForField(column.Field)
+	prev := decorator.ForField(column.PrevField)
+
+	statements := []string{}
+
+	if prev.Unique != nil && decorators.Unique == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;\n", table, name))
+	}
+
+	if prev.Id != nil && decorators.Id == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY;\n", table))
+	}
+
+	if definition != prevDefinition {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;\n", table, definition))
+	}
+
+	if decorators.Id != nil && prev.Id == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);\n", table, name))
+	}
+
+	if decorators.Unique != nil && prev.Unique == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD UNIQUE KEY %s (%s);\n", table, name, name))
+	}
+
+	return statements, nil
 }
 
-func (g *MysqlGenerator) typeToSqliteType(decType *ast.DeclarationType) ([]byte, bool) {
+func (g *MysqlGenerator) GenerateField(ast *ast.Ast, item *ast.Declaration) (string, error) {
+	g.ast = ast
+
+	column, err := g.generateItem(item)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(column), nil
+}
+
+func (g *MysqlGenerator) generateItem(item *ast.Declaration) (string, error) {
+	if g.isTypeModel(item.DeclarationType) {
+		return "", nil
+	}
+
+	definition, err := g.columnDefinition(item)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	decorators := decorator.ForField(item)
+
+	sb.WriteString("  ")
+	sb.WriteString(definition)
+
+	if decorators.Unique != nil {
+		sb.WriteString(" UNIQUE")
+	}
+
+	if decorators.Id != nil {
+		sb.WriteString(" PRIMARY KEY")
+	}
+
+	return sb.String(), nil
+}
+
+// columnDefinition returns the name, type, nullability and default of a
+// column.
+func (g *MysqlGenerator) columnDefinition(item *ast.Declaration) (string, error) {
+	decType, ok := g.typeToMysqlType(item.DeclarationType)
+	if !ok {
+		return "", fmt.Errorf("invalid type %s", item.DeclarationType.Name)
+	}
+
+	var sb strings.Builder
+	decorators := decorator.ForField(item)
+
+	sb.WriteString(g.quote(decorator.ColumnName(g.ast, item)))
+	sb.WriteString(" ")
+	sb.Write(decType)
+
+	if decorators.Nullable == nil {
+		sb.WriteString(" NOT NULL")
+	}
+
+	if decorators.Default != nil {
+		switch decorators.Default.Function {
+		case "":
+			sb.WriteString(" DEFAULT ")
+			sb.WriteString(sqlLiteral(decorators.Default.Value, "TRUE", "FALSE"))
+		case decorator.FunctionNow:
+			sb.WriteString(" DEFAULT CURRENT_TIMESTAMP(6)")
+		case decorator.FunctionAutoincrement:
+			sb.WriteString(" AUTO_INCREMENT")
+		case decorator.FunctionUuid:
+			sb.WriteString(" DEFAULT (UUID())")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func (g *MysqlGenerator) typeToMysqlType(decType *ast.DeclarationType) ([]byte, bool) {
 	sqlType, ok := mysqlTypes[decType.Type]
 	if ok {
 		return sqlType, true
@@ -146,7 +474,7 @@ func (g *MysqlGenerator) typeToSqliteType(decType *ast.DeclarationType) ([]byte,
 			if len(enum.Items) > 0 {
 				enumType := enum.Items[0].Value.Type
 				if enumType == ast.ValueTypeString {
-					return []byte("VARCHAR(255)"), true
+					return []byte(g.enumType(enum)), true
 				}
 				if enumType == ast.ValueTypeInt {
 					return []byte("INT"), true
@@ -158,6 +486,17 @@ func (g *MysqlGenerator) typeToSqliteType(decType *ast.DeclarationType) ([]byte,
 	return []byte{}, false
 }
 
+// enumType returns a native ENUM column type holding the values of a string
+// enum.
+func (g *MysqlGenerator) enumType(enum *ast.Enum) string {
+	values := make([]string, len(enum.Items))
+	for i, item := range enum.Items {
+		values[i] = sqlString(item.Value.Value)
+	}
+
+	return "ENUM(" + strings.Join(values, ", ") + ")"
+}
+
 func (g *MysqlGenerator) isTypeModel(decType *ast.DeclarationType) bool {
 	name := decType.Name
 
@@ -170,12 +509,6 @@ func (g *MysqlGenerator) isTypeModel(decType *ast.DeclarationType) bool {
 	return false
 }
 
-func (g *MysqlGenerator) getDecorator(name string, decorators []*ast.Decorator) (*ast.Decorator, bool) {
-	for _, dec := range decorators {
-		if dec.Name.Identifier == name {
-			return dec, true
-		}
-	}
-
-	return nil, false
+func (g *MysqlGenerator) quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
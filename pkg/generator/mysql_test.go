@@ -0,0 +1,122 @@
+package generator_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/generator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func TestMysqlGenerator(t *testing.T) {
+	input := `
+db {
+  provider = "mysql"
+  url = "root@tcp(localhost:3306)/test"
+}
+
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+enum Level {
+  low = 1
+  high = 2
+}
+
+model User {
+  id      string  @id @default(uuid())
+  name    string
+  surname string
+  role    Role
+  level   Level
+  score   real
+  birth   DateTime
+  posts   Post[]
+}
+
+model Post {
+  id        int     @id @default(autoincrement())
+  title     string
+  content   string  @nullable
+  public    bool    @default(false)
+  views     int     @default(0)
+  slug      string  @unique
+  createdAt DateTime @default(now())
+  author    User    @relation(field: authorId, reference: id)
+  authorId  string
+
+  @@unique([title, authorId])
+}`
+
+	expected := map[string]string{
+		"1_User.sql": "CREATE TABLE IF NOT EXISTS `User` (\n" +
+			"  `id` VARCHAR(255) NOT NULL DEFAULT (UUID()) PRIMARY KEY,\n" +
+			"  `name` VARCHAR(255) NOT NULL,\n" +
+			"  `surname` VARCHAR(255) NOT NULL,\n" +
+			"  `role` ENUM('admin', 'user') NOT NULL,\n" +
+			"  `level` INT NOT NULL,\n" +
+			"  `score` DOUBLE NOT NULL,\n" +
+			"  `birth` DATETIME(6) NOT NULL\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;\n\n",
+		"2_Post.sql": "CREATE TABLE IF NOT EXISTS `Post` (\n" +
+			"  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,\n" +
+			"  `title` VARCHAR(255) NOT NULL,\n" +
+			"  `content` VARCHAR(255),\n" +
+			"  `public` BOOLEAN NOT NULL DEFAULT FALSE,\n" +
+			"  `views` INT NOT NULL DEFAULT 0,\n" +
+			"  `slug` VARCHAR(255) NOT NULL UNIQUE,\n" +
+			"  `createdAt` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n" +
+			"  `authorId` VARCHAR(255) NOT NULL,\n" +
+			"  CONSTRAINT `Post_title_authorId_key` UNIQUE (`title`, `authorId`),\n" +
+			"  CONSTRAINT `Post_authorId_fkey` FOREIGN KEY (`authorId`) REFERENCES `User` (`id`)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;\n\n",
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	generator := generator.NewMysqlGenerator()
+
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestMysqlGeneratorRegistered(t *testing.T) {
+	gen, err := generator.GetGenerator("mysql")
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	if _, ok := gen.(*generator.MysqlGenerator); !ok {
+		t.Fatalf("expected mysql to be a MysqlGenerator but got %T", gen)
+	}
+
+	other, _ := generator.GetGenerator("mysql")
+	if other == gen {
+		t.Fatalf("expected a new MysqlGenerator on every call")
+	}
+}
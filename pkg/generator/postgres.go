@@ -0,0 +1,667 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/migration"
+)
+
+var postgresTypes = map[ast.VariableType][]byte{
+	ast.VariableTypeInt:      []byte("BIGINT"),
+	ast.VariableTypeReal:     []byte("DOUBLE PRECISION"),
+	ast.VariableTypeBool:     []byte("BOOLEAN"),
+	ast.VariableTypeString:   []byte("TEXT"),
+	ast.VariableTypeDateTime: []byte("TIMESTAMPTZ"),
+}
+
+func init() {
+	RegisterGenerator("postgres", func() Generator { return NewPostgresGenerator() })
+}
+
+type PostgresGenerator struct {
+	ast    *ast.Ast
+	writer io.Writer
+	cfg    *GeneratorConfig
+}
+
+func NewPostgresGenerator() *PostgresGenerator {
+	g := PostgresGenerator{}
+
+	return &g
+}
+
+func (g *PostgresGenerator) GenerateAll(ast *ast.Ast, cfg *GeneratorConfig) error {
+	g.ast = ast
+	g.cfg = cfg
+
+	err := os.MkdirAll(path.Join(g.cfg.WorkingDir, "migrations"), 0755)
+	if err != nil {
+		return err
+	}
+
+	for idx, model := range migration.SortModels(ast.Models) {
+		err := g.generateModel(model, idx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for idx, table := range analyzer.JoinTables(ast) {
+		err := g.generateJoinTable(table, len(ast.Models)+idx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *PostgresGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name string) error {
+	g.ast = ast
+	g.cfg = cfg
+
+	err := os.MkdirAll(path.Join(g.cfg.WorkingDir, "migrations"), 0755)
+	if err != nil {
+		return err
+	}
+
+	isExist := false
+
+	for idx, model := range migration.SortModels(ast.Models) {
+		if model.Name.Identifier == name {
+			isExist = true
+
+			err := g.generateModel(model, idx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx, table := range analyzer.JoinTables(ast) {
+		if table.Name == name {
+			isExist = true
+
+			err := g.generateJoinTable(table, len(ast.Models)+idx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !isExist {
+		return fmt.Errorf("model or join table %s not found", name)
+	}
+
+	return nil
+}
+
+func (g *PostgresGenerator) generateModel(model *ast.Model, idx int) error {
+	sql, err := g.createTable(model)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, model.Name.Identifier)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	g.writer = f
+
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
+
+	return nil
+}
+
+func (g *PostgresGenerator) createTable(model *ast.Model) (string, error) {
+	var sb strings.Builder
+
+	// enum types have to exist before the table using them is created
+	generated := map[string]struct{}{}
+	for _, item := range model.Items {
+		enum, ok := g.getEnum(item.DeclarationType)
+		if !ok || !g.isStringEnum(enum) {
+			continue
+		}
+		if _, ok := generated[enum.Name.Identifier]; ok {
+			continue
+		}
+		generated[enum.Name.Identifier] = struct{}{}
+
+		sb.WriteString(g.createEnum(enum))
+		sb.WriteString("\n")
+	}
+
+	lines := []string{}
+	for _, item := range model.Items {
+		line, err := g.generateItem(item)
+		if err != nil {
+			return "", err
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	constraints, indexes := tableConstraints(g.ast, model)
+	for _, constraint := range constraints {
+		lines = append(lines, "  "+g.constraint(constraint))
+	}
+
+	table := decorator.TableName(g.ast, model)
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(table))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	for _, index := range indexes {
+		sb.WriteString(g.createIndex(table, index))
+	}
+
+	return sb.String(), nil
+}
+
+func (g *PostgresGenerator) constraint(constraint *migration.ConstraintChange) string {
+	columns := columnList(constraint.Columns, g.quote)
+
+	switch constraint.Kind {
+	case migration.ConstraintPrimaryKey:
+		return fmt.Sprintf("PRIMARY KEY (%s)", columns)
+	case migration.ConstraintForeignKey:
+		return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)%s", columns, g.quote(constraint.ReferenceTable), g.quote(constraint.ReferenceColumn), foreignKeyActions(constraint.Relation))
+	}
+
+	return fmt.Sprintf("UNIQUE (%s)", columns)
+}
+
+func (g *PostgresGenerator) createIndex(table string, index *migration.ConstraintChange) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s);\n", g.quote(index.Name), g.quote(table), columnList(index.Columns, g.quote))
+}
+
+// createEnum creates the enum type unless it already exists, postgres has
+// no CREATE TYPE IF NOT EXISTS.
+func (g *PostgresGenerator) createEnum(enum *ast.Enum) string {
+	values := make([]string, len(enum.Items))
+	for i, item := range enum.Items {
+		values[i] = sqlString(item.Value.Value)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("DO $$ BEGIN\n")
+	sb.WriteString("  CREATE TYPE ")
+	sb.WriteString(g.quote(enum.Name.Identifier))
+	sb.WriteString(" AS ENUM (")
+	sb.WriteString(strings.Join(values, ", "))
+	sb.WriteString(");\n")
+	sb.WriteString("EXCEPTION\n")
+	sb.WriteString("  WHEN duplicate_object THEN null;\n")
+	sb.WriteString("END $$;\n")
+
+	return sb.String()
+}
+
+// generateJoinTable creates the table of a many-to-many relation, a row links
+// one row of each model and is removed with either of them.
+func (g *PostgresGenerator) generateJoinTable(table *analyzer.JoinTable, idx int) error {
+	sql, err := g.createJoinTable(table)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, table.Name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	g.writer = f
+
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
+
+	return nil
+}
+
+func (g *PostgresGenerator) createJoinTable(table *analyzer.JoinTable) (string, error) {
+	lines := []string{}
+	keys := []string{}
+	for _, end := range []*analyzer.RelationEnd{table.A, table.B} {
+		id, ok := idField(end.Model)
+		if !ok {
+			return "", fmt.Errorf("model %s has no @id field", end.Model.Name.Identifier)
+		}
+
+		decType, ok := g.typeToPostgresType(id.DeclarationType)
+		if !ok {
+			return "", fmt.Errorf("invalid type %s", id.DeclarationType.Name)
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s %s NOT NULL", g.quote(end.Column), decType))
+		keys = append(keys, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE CASCADE", g.quote(end.Column), g.quote(decorator.TableName(g.ast, end.Model)), g.quote(decorator.ColumnName(g.ast, id))))
+	}
+
+	lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s, %s)", g.quote(table.A.Column), g.quote(table.B.Column)))
+	lines = append(lines, keys...)
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(table.Name))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	return sb.String(), nil
+}
+
+func (g *PostgresGenerator) GenerateMigration(m *migration.Migration, cfg *GeneratorConfig, name string) error {
+	g.cfg = cfg
+
+	up, err := g.migrate(m.Changes)
+	if err != nil {
+		return err
+	}
+
+	down, err := g.migrate(m.Down())
+	if err != nil {
+		return err
+	}
+
+	// the enum types created by the up migration outlive its tables
+	drops := []string{}
+	for _, enum := range g.createdEnums(m) {
+		drops = append(drops, fmt.Sprintf("DROP TYPE IF EXISTS %s;\n", g.quote(enum)))
+	}
+	if len(drops) > 0 {
+		if down != "" {
+			down += "\n"
+		}
+		down += strings.Join(drops, "")
+	}
+
+	return writeMigration(cfg, name, up, down)
+}
+
+// createdEnums returns the string enums a column uses after the migration
+// but not before it, the up migration creates their types.
+func (g *PostgresGenerator) createdEnums(m *migration.Migration) []string {
+	prev := map[string]struct{}{}
+	for _, name := range g.usedEnums(m.From) {
+		prev[name] = struct{}{}
+	}
+
+	created := []string{}
+	for _, name := range g.usedEnums(m.To) {
+		if _, ok := prev[name]; !ok {
+			created = append(created, name)
+		}
+	}
+
+	return created
+}
+
+// usedEnums returns the string enums the columns of a schema use, in the
+// order of their first use.
+func (g *PostgresGenerator) usedEnums(schema *ast.Ast) []string {
+	if schema == nil {
+		return []string{}
+	}
+
+	g.ast = schema
+	seen := map[string]struct{}{}
+	enums := []string{}
+	for _, model := range schema.Models {
+		for _, item := range model.Items {
+			enum, ok := g.getEnum(item.DeclarationType)
+			if !ok || !g.isStringEnum(enum) {
+				continue
+			}
+			if _, ok := seen[enum.Name.Identifier]; ok {
+				continue
+			}
+			seen[enum.Name.Identifier] = struct{}{}
+			enums = append(enums, enum.Name.Identifier)
+		}
+	}
+
+	return enums
+}
+
+func (g *PostgresGenerator) migrate(changes []*migration.Change) (string, error) {
+	statements := []string{}
+
+	for _, change := range changes {
+		g.ast = change.To
+
+		sql := ""
+		var err error
+
+		switch change.Type {
+		case migration.ChangeCreateTable:
+			sql, err = g.createTable(change.Model)
+		case migration.ChangeDropTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)))
+		case migration.ChangeRenameTable:
+			sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)), g.quote(decorator.TableName(change.To, change.Model)))
+		case migration.ChangeAlterTable:
+			sql, err = g.alterTable(change)
+		case migration.ChangeCreateJoinTable:
+			sql, err = g.createJoinTable(change.JoinTable)
+		case migration.ChangeDropJoinTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(change.PrevJoinTable.Name))
+		case migration.ChangeAlterEnum:
+			sql = g.alterEnum(change)
+		}
+
+		if err != nil {
+			return "", err
+		}
+		if sql != "" {
+			statements = append(statements, sql)
+		}
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+// alterEnum adds the new values of a string enum. Postgres can not drop the
+// value of an enum, a dropped value is left in the type.
+func (g *PostgresGenerator) alterEnum(change *migration.Change) string {
+	if !g.isStringEnum(change.Enum) {
+		return ""
+	}
+
+	prevValues := map[string]struct{}{}
+	for _, item := range change.PrevEnum.Items {
+		prevValues[item.Value.Value] = struct{}{}
+	}
+
+	var sb strings.Builder
+	for _, item := range change.Enum.Items {
+		if _, ok := prevValues[item.Value.Value]; ok {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s;\n", g.quote(change.Enum.Name.Identifier), sqlString(item.Value.Value)))
+	}
+
+	return sb.String()
+}
+
+func (g *PostgresGenerator) alterTable(change *migration.Change) (string, error) {
+	table := g.quote(change.Table)
+	statements := []string{}
+
+	for _, constraint := range change.Constraints {
+		if constraint.Type != migration.ConstraintDrop {
+			continue
+		}
+
+		if constraint.Kind == migration.ConstraintIndex {
+			statements = append(statements, fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", g.quote(constraint.Name)))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", table, g.quote(constraint.Name)))
+		}
+	}
+
+	for _, column := range change.Columns {
+		switch column.Type {
+		case migration.ColumnAdd:
+			line, err := g.generateItem(column.Field)
+			if err != nil {
+				return "", err
+			}
+
+			statements = append(statements, g.enumOf(column.Field)...)
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", table, strings.TrimSpace(line)))
+		case migration.ColumnDrop:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", table, g.quote(decorator.ColumnName(change.From, column.PrevField))))
+		case migration.ColumnRename:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;\n", table, g.quote(decorator.ColumnName(change.From, column.PrevField)), g.quote(decorator.ColumnName(g.ast, column.Field))))
+		case migration.ColumnAlter:
+			alter, err := g.alterColumn(change, column)
+			if err != nil {
+				return "", err
+			}
+
+			statements = append(statements, alter...)
+		}
+	}
+
+	for _, constraint := range change.Constraints {
+		if constraint.Type != migration.ConstraintAdd {
+			continue
+		}
+
+		if constraint.Kind == migration.ConstraintIndex {
+			statements = append(statements, g.createIndex(change.Table, constraint))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;\n", table, g.quote(constraint.Name), g.constraint(constraint)))
+		}
+	}
+
+	return strings.Join(statements, ""), nil
+}
+
+// alterColumn changes the type, nullability, default and keys of a column
+// one clause at a time.
+func (g *PostgresGenerator) alterColumn(change *migration.Change, column *migration.ColumnChange) ([]string, error) {
+	table := g.quote(change.Table)
+	name := decorator.ColumnName(g.ast, column.Field)
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ", table, g.quote(name))
+
+	decType, ok := g.typeToPostgresType(column.Field.DeclarationType)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %s", column.Field.DeclarationType.Name)
+	}
+
+	g.ast = change.From
+	prevType, ok := g.typeToPostgresType(column.PrevField.DeclarationType)
+	g.ast = change.To
+	if !ok {
+		return nil, fmt.Errorf("invalid type %s", column.PrevField.DeclarationType.Name)
+	}
+
+	decorators := decorator.ForField(column.Field)
+	prev := decorator.ForField(column.PrevField)
+
+	statements := []string{}
+
+	if prev.Unique != nil && decorators.Unique == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", table, g.quote(migration.UniqueName(change.Table, []string{name}))))
+	}
+
+	if prev.Id != nil && decorators.Id == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", table, g.quote(migration.PrimaryKeyName(change.Table))))
+	}
+
+	prevDefault, prevIdentity := g.columnDefault(prev.Default)
+	nextDefault, nextIdentity := g.columnDefault(decorators.Default)
+
+	if prevIdentity && !nextIdentity {
+		statements = append(statements, alter+"DROP IDENTITY IF EXISTS;\n")
+	}
+
+	if prevDefault != "" && prevDefault != nextDefault {
+		statements = append(statements, alter+"DROP DEFAULT;\n")
+	}
+
+	if string(decType) != string(prevType) {
+		statements = append(statements, g.enumOf(column.Field)...)
+		statements = append(statements, fmt.Sprintf("%sTYPE %s USING %s::%s;\n", alter, decType, g.quote(name), decType))
+	}
+
+	if prev.Nullable == nil && decorators.Nullable != nil {
+		statements = append(statements, alter+"DROP NOT NULL;\n")
+	}
+
+	if prev.Nullable != nil && decorators.Nullable == nil {
+		statements = append(statements, alter+"SET NOT NULL;\n")
+	}
+
+	if nextDefault != "" && prevDefault != nextDefault {
+		statements = append(statements, alter+"SET DEFAULT "+nextDefault+";\n")
+	}
+
+	if nextIdentity && !prevIdentity {
+		statements = append(statements, alter+"ADD GENERATED BY DEFAULT AS IDENTITY;\n")
+	}
+
+	if decorators.Id != nil && prev.Id == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);\n", table, g.quote(name)))
+	}
+
+	if decorators.Unique != nil && prev.Unique == nil {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);\n", table, g.quote(migration.UniqueName(change.Table, []string{name})), g.quote(name)))
+	}
+
+	return statements, nil
+}
+
+// enumOf creates the string enum type of a column, which may be new in a
+// migration altering the table.
+func (g *PostgresGenerator) enumOf(item *ast.Declaration) []string {
+	enum, ok := g.getEnum(item.DeclarationType)
+	if !ok || !g.isStringEnum(enum) {
+		return []string{}
+	}
+
+	return []string{g.createEnum(enum)}
+}
+
+func (g *PostgresGenerator) GenerateField(ast *ast.Ast, item *ast.Declaration) (string, error) {
+	g.ast = ast
+
+	column, err := g.generateItem(item)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(column), nil
+}
+
+func (g *PostgresGenerator) generateItem(item *ast.Declaration) (string, error) {
+	decType, ok := g.typeToPostgresType(item.DeclarationType)
+	if !ok {
+		if g.isTypeModel(item.DeclarationType) {
+			return "", nil
+		} else {
+			return "", fmt.Errorf("invalid type %s", item.DeclarationType.Name)
+		}
+	}
+
+	var sb strings.Builder
+	decorators := decorator.ForField(item)
+
+	sb.WriteString("  ")
+	sb.WriteString(g.quote(decorator.ColumnName(g.ast, item)))
+	sb.WriteString(" ")
+	sb.Write(decType)
+
+	if decorators.Nullable == nil {
+		sb.WriteString(" NOT NULL")
+	}
+
+	value, identity := g.columnDefault(decorators.Default)
+	if value != "" {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(value)
+	}
+	if identity {
+		sb.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+	}
+
+	if decorators.Unique != nil {
+		sb.WriteString(" UNIQUE")
+	}
+
+	if decorators.Id != nil {
+		sb.WriteString(" PRIMARY KEY")
+	}
+
+	return sb.String(), nil
+}
+
+// columnDefault returns the DEFAULT expression of a column, autoincrement()
+// has none as it makes the column an identity column.
+func (g *PostgresGenerator) columnDefault(def *decorator.Default) (string, bool) {
+	if def == nil {
+		return "", false
+	}
+
+	switch def.Function {
+	case "":
+		return sqlLiteral(def.Value, "TRUE", "FALSE"), false
+	case decorator.FunctionNow:
+		return "CURRENT_TIMESTAMP", false
+	case decorator.FunctionAutoincrement:
+		return "", true
+	case decorator.FunctionUuid:
+		return "gen_random_uuid()::text", false
+	}
+
+	return "", false
+}
+
+func (g *PostgresGenerator) typeToPostgresType(decType *ast.DeclarationType) ([]byte, bool) {
+	sqlType, ok := postgresTypes[decType.Type]
+	if ok {
+		return sqlType, true
+	}
+
+	enum, ok := g.getEnum(decType)
+	if ok && len(enum.Items) > 0 {
+		if g.isStringEnum(enum) {
+			return []byte(g.quote(enum.Name.Identifier)), true
+		}
+		if enum.Items[0].Value.Type == ast.ValueTypeInt {
+			return []byte("INTEGER"), true
+		}
+	}
+
+	return []byte{}, false
+}
+
+func (g *PostgresGenerator) getEnum(decType *ast.DeclarationType) (*ast.Enum, bool) {
+	name := decType.Name
+
+	for _, enum := range g.ast.Enums {
+		if enum.Name.Identifier == name {
+			return enum, true
+		}
+	}
+
+	return nil, false
+}
+
+func (g *PostgresGenerator) isStringEnum(enum *ast.Enum) bool {
+	return len(enum.Items) > 0 && enum.Items[0].Value.Type == ast.ValueTypeString
+}
+
+func (g *PostgresGenerator) isTypeModel(decType *ast.DeclarationType) bool {
+	name := decType.Name
+
+	for _, model := range g.ast.Models {
+		if model.Name.Identifier == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quote quotes an identifier, without quotes postgres folds names to lower
+// case and model names like User are reserved words.
+func (g *PostgresGenerator) quote(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
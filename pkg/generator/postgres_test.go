@@ -0,0 +1,313 @@
+package generator_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/generator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func TestPostgresGenerator(t *testing.T) {
+	input := `
+db {
+  provider = "postgres"
+  url = "postgres://localhost/test"
+}
+
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+enum Level {
+  low = 1
+  high = 2
+}
+
+model User {
+  id      string  @id @default(uuid())
+  name    string
+  surname string
+  role    Role
+  level   Level
+  score   real
+  birth   DateTime
+  posts   Post[]
+}
+
+model Post {
+  id        int     @id @default(autoincrement())
+  title     string
+  content   string  @nullable
+  public    bool    @default(false)
+  views     int     @default(0)
+  slug      string  @unique
+  createdAt DateTime @default(now())
+  author    User    @relation(field: authorId, reference: id)
+  authorId  string
+
+  @@unique([title, authorId])
+}`
+
+	expected := map[string]string{
+		"1_User.sql": `DO $$ BEGIN
+  CREATE TYPE "Role" AS ENUM ('admin', 'user');
+EXCEPTION
+  WHEN duplicate_object THEN null;
+END $$;
+
+CREATE TABLE IF NOT EXISTS "User" (
+  "id" TEXT NOT NULL DEFAULT gen_random_uuid()::text PRIMARY KEY,
+  "name" TEXT NOT NULL,
+  "surname" TEXT NOT NULL,
+  "role" "Role" NOT NULL,
+  "level" INTEGER NOT NULL,
+  "score" DOUBLE PRECISION NOT NULL,
+  "birth" TIMESTAMPTZ NOT NULL
+);
+
+`,
+		"2_Post.sql": `CREATE TABLE IF NOT EXISTS "Post" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "title" TEXT NOT NULL,
+  "content" TEXT,
+  "public" BOOLEAN NOT NULL DEFAULT FALSE,
+  "views" BIGINT NOT NULL DEFAULT 0,
+  "slug" TEXT NOT NULL UNIQUE,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "authorId" TEXT NOT NULL,
+  UNIQUE ("title", "authorId"),
+  FOREIGN KEY ("authorId") REFERENCES "User" ("id")
+);
+
+`,
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	generator := generator.NewPostgresGenerator()
+
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestPostgresGeneratorMigration(t *testing.T) {
+	from := `
+enum Role {
+  admin = "admin"
+}
+
+model User {
+  id      int     @id
+  name    string
+  role    Role
+}`
+
+	to := `
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model User {
+  id      int     @id
+  name    string  @nullable @default("anonymous")
+  role    Role
+  views   int     @default(0)
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `ALTER TYPE "Role" ADD VALUE IF NOT EXISTS 'user';
+
+ALTER TABLE "User" ALTER COLUMN "name" DROP NOT NULL;
+ALTER TABLE "User" ALTER COLUMN "name" SET DEFAULT 'anonymous';
+ALTER TABLE "User" ADD COLUMN "views" BIGINT NOT NULL DEFAULT 0;
+`,
+		"1_update.down.sql": `ALTER TABLE "User" DROP COLUMN "views";
+ALTER TABLE "User" ALTER COLUMN "name" DROP DEFAULT;
+ALTER TABLE "User" ALTER COLUMN "name" SET NOT NULL;
+`,
+	}
+
+	generateMigration(t, generator.NewPostgresGenerator(), from, to, expected)
+}
+
+func TestPostgresGeneratorEnumMigration(t *testing.T) {
+	to := `
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model User {
+  id      int     @id
+  role    Role
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `DO $$ BEGIN
+  CREATE TYPE "Role" AS ENUM ('admin', 'user');
+EXCEPTION
+  WHEN duplicate_object THEN null;
+END $$;
+
+CREATE TABLE IF NOT EXISTS "User" (
+  "id" BIGINT NOT NULL PRIMARY KEY,
+  "role" "Role" NOT NULL
+);
+`,
+		"1_update.down.sql": `DROP TABLE IF EXISTS "User";
+
+DROP TYPE IF EXISTS "Role";
+`,
+	}
+
+	generateMigration(t, generator.NewPostgresGenerator(), "", to, expected)
+}
+
+func TestPostgresGeneratorMapped(t *testing.T) {
+	input := `
+model User {
+  id      int     @id
+  name    string  @map("user_name")
+
+  @@map("users")
+}
+
+model Membership {
+  userId  int     @map("user_id")
+  groupId int     @map("group_id")
+  role    string
+  user    User    @relation(field: userId, reference: id)
+
+  @@id([userId, groupId])
+  @@index([role])
+  @@index([groupId, role], name: "memberships_by_group")
+  @@map("memberships")
+}`
+
+	expected := map[string]string{
+		"2_Membership.sql": `CREATE TABLE IF NOT EXISTS "memberships" (
+  "user_id" BIGINT NOT NULL,
+  "group_id" BIGINT NOT NULL,
+  "role" TEXT NOT NULL,
+  PRIMARY KEY ("user_id", "group_id"),
+  FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+CREATE INDEX IF NOT EXISTS "memberships_role_idx" ON "memberships" ("role");
+CREATE INDEX IF NOT EXISTS "memberships_by_group" ON "memberships" ("group_id", "role");
+
+`,
+	}
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+
+	err = generator.NewPostgresGenerator().GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestPostgresGeneratorRenameMigration(t *testing.T) {
+	from := `
+model User {
+  id      int     @id
+  name    string
+}`
+
+	to := `
+model User {
+  id      int     @id
+  name    string  @map("user_name")
+
+  @@index([name])
+  @@map("users")
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `ALTER TABLE "User" RENAME TO "users";
+
+ALTER TABLE "users" RENAME COLUMN "name" TO "user_name";
+CREATE INDEX IF NOT EXISTS "users_user_name_idx" ON "users" ("user_name");
+`,
+		"1_update.down.sql": `DROP INDEX IF EXISTS "users_user_name_idx";
+ALTER TABLE "users" RENAME COLUMN "user_name" TO "name";
+
+ALTER TABLE "users" RENAME TO "User";
+`,
+	}
+
+	generateMigration(t, generator.NewPostgresGenerator(), from, to, expected)
+}
+
+func TestPostgresGeneratorNamingMigration(t *testing.T) {
+	from := `
+model BlogPost {
+  id        int     @id
+  createdAt DateTime
+}`
+
+	to := `
+db {
+  naming = "snake_plural"
+}
+
+model BlogPost {
+  id        int     @id
+  createdAt DateTime
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `ALTER TABLE "BlogPost" RENAME TO "blog_posts";
+
+ALTER TABLE "blog_posts" RENAME COLUMN "createdAt" TO "created_at";
+`,
+		"1_update.down.sql": `ALTER TABLE "blog_posts" RENAME COLUMN "created_at" TO "createdAt";
+
+ALTER TABLE "blog_posts" RENAME TO "BlogPost";
+`,
+	}
+
+	generateMigration(t, generator.NewPostgresGenerator(), from, to, expected)
+}
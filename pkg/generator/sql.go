@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/migration"
+)
+
+var referentialActions = map[string]string{
+	decorator.ActionCascade:  "CASCADE",
+	decorator.ActionSetNull:  "SET NULL",
+	decorator.ActionRestrict: "RESTRICT",
+	decorator.ActionNoAction: "NO ACTION",
+}
+
+// idField returns the @id field of the model.
+func idField(model *ast.Model) (*ast.Declaration, bool) {
+	for _, item := range model.Items {
+		if decorator.ForField(item).Id != nil {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+var constraintOrder = map[migration.ConstraintKind]int{
+	migration.ConstraintPrimaryKey: 0,
+	migration.ConstraintUnique:     1,
+	migration.ConstraintForeignKey: 2,
+}
+
+// tableConstraints returns the constraints of the CREATE TABLE statement of
+// a model, its primary key, unique constraints and foreign keys in this
+// order, and apart from them its indexes.
+func tableConstraints(schema *ast.Ast, model *ast.Model) ([]*migration.ConstraintChange, []*migration.ConstraintChange) {
+	constraints := []*migration.ConstraintChange{}
+	indexes := []*migration.ConstraintChange{}
+
+	for _, constraint := range migration.Constraints(schema, model) {
+		if constraint.Kind == migration.ConstraintIndex {
+			indexes = append(indexes, constraint)
+		} else {
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	sort.SliceStable(constraints, func(i, j int) bool {
+		return constraintOrder[constraints[i].Kind] < constraintOrder[constraints[j].Kind]
+	})
+
+	return constraints, indexes
+}
+
+// foreignKeyActions returns the ON DELETE and ON UPDATE clauses of a
+// relation, prefixed with a space.
+func foreignKeyActions(relation *decorator.Relation) string {
+	actions := ""
+
+	if relation.OnDelete != "" {
+		actions += " ON DELETE " + referentialActions[relation.OnDelete]
+	}
+
+	if relation.OnUpdate != "" {
+		actions += " ON UPDATE " + referentialActions[relation.OnUpdate]
+	}
+
+	return actions
+}
+
+// sqlString quotes a string literal.
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sqlLiteral renders a literal @default value. Booleans are passed in as
+// the dialects store them differently.
+func sqlLiteral(value *ast.Value, boolTrue string, boolFalse string) string {
+	switch value.Type {
+	case ast.ValueTypeBool:
+		if value.Value == "true" {
+			return boolTrue
+		}
+		return boolFalse
+	case ast.ValueTypeNull:
+		return "NULL"
+	case ast.ValueTypeString:
+		return sqlString(value.Value)
+	case ast.ValueTypeInt:
+		// hexadecimal literals are not understood by every dialect
+		n, err := strconv.ParseInt(value.Value, 0, 64)
+		if err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	}
+
+	return value.Value
+}
+
+// columnList joins the quoted names of columns.
+func columnList(columns []string, quote func(string) string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quote(column)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// writeMigration writes the statements of a migration and of its revert to
+// <name>.up.sql and <name>.down.sql.
+func writeMigration(cfg *GeneratorConfig, name string, up string, down string) error {
+	dir := path.Join(cfg.WorkingDir, "migrations")
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(dir, name+".up.sql"), []byte(up), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(dir, name+".down.sql"), []byte(down), 0644)
+}
@@ -5,8 +5,12 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 
+	"github.com/gophoria/gophoria/pkg/analyzer"
 	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/migration"
 )
 
 var sqlite3Types = map[ast.VariableType][]byte{
@@ -17,8 +21,15 @@ var sqlite3Types = map[ast.VariableType][]byte{
 	ast.VariableTypeDateTime: []byte("TEXT"),
 }
 
+// sqlite3Uuid builds a random version 4 uuid, sqlite has no uuid function.
+const sqlite3Uuid = "(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))))"
+
+// sqlite3Now is the current time in the RFC 3339 format DateTime stores,
+// CURRENT_TIMESTAMP has a space instead of the T and no time zone.
+const sqlite3Now = "(strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))"
+
 func init() {
-	RegisterGenerator("sqlite3", NewSqlite3Generator())
+	RegisterGenerator("sqlite3", func() Generator { return NewSqlite3Generator() })
 }
 
 type Sqlite3Generator struct {
@@ -42,13 +53,20 @@ func (g *Sqlite3Generator) GenerateAll(ast *ast.Ast, cfg *GeneratorConfig) error
 		return err
 	}
 
-	for idx, model := range ast.Models {
+	for idx, model := range migration.SortModels(ast.Models) {
 		err := g.generateModel(model, idx)
 		if err != nil {
 			return err
 		}
 	}
 
+	for idx, table := range analyzer.JoinTables(ast) {
+		err := g.generateJoinTable(table, len(ast.Models)+idx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -60,13 +78,10 @@ func (g *Sqlite3Generator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name str
 	if err != nil {
 		return err
 	}
-	if err != nil {
-		return err
-	}
 
 	isExist := false
 
-	for idx, model := range ast.Models {
+	for idx, model := range migration.SortModels(ast.Models) {
 		if model.Name.Identifier == name {
 			isExist = true
 
@@ -77,14 +92,32 @@ func (g *Sqlite3Generator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name str
 		}
 	}
 
+	for idx, table := range analyzer.JoinTables(ast) {
+		if table.Name == name {
+			isExist = true
+
+			err := g.generateJoinTable(table, len(ast.Models)+idx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if !isExist {
-		return fmt.Errorf("model %s not found", name)
+		return fmt.Errorf("model or join table %s not found", name)
 	}
 
 	return nil
 }
 
 func (g *Sqlite3Generator) generateModel(model *ast.Model, idx int) error {
+	table := decorator.TableName(g.ast, model)
+	sql, err := g.createTable(model, table)
+	if err != nil {
+		return err
+	}
+	sql += g.createIndexes(model, table)
+
 	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, model.Name.Identifier)))
 	if err != nil {
 		return err
@@ -93,47 +126,381 @@ func (g *Sqlite3Generator) generateModel(model *ast.Model, idx int) error {
 
 	g.writer = f
 
-	g.writer.Write([]byte("CREATE TABLE IF NOT EXISTS "))
-	g.writer.Write([]byte(model.Name.Token.Literal))
-	g.writer.Write([]byte(" (\n"))
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
+
+	return nil
+}
 
-	for idx, item := range model.Items {
-		err := g.generateItem(item, idx == len(model.Items)-1)
+// createTable returns the CREATE TABLE statement of a model, name is the
+// name of the table as a rebuilt table is created under a temporary name.
+func (g *Sqlite3Generator) createTable(model *ast.Model, name string) (string, error) {
+	lines := []string{}
+	for _, item := range model.Items {
+		line, err := g.generateItem(item)
 		if err != nil {
-			return err
+			return "", err
+		}
+		if line != "" {
+			lines = append(lines, line)
 		}
 	}
 
-	g.writer.Write([]byte(");\n\n"))
+	constraints, _ := tableConstraints(g.ast, model)
+	for _, constraint := range constraints {
+		lines = append(lines, "  "+g.constraint(constraint))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(name))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	return sb.String(), nil
+}
+
+func (g *Sqlite3Generator) constraint(constraint *migration.ConstraintChange) string {
+	columns := columnList(constraint.Columns, g.quote)
+
+	switch constraint.Kind {
+	case migration.ConstraintPrimaryKey:
+		return fmt.Sprintf("PRIMARY KEY (%s)", columns)
+	case migration.ConstraintForeignKey:
+		return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)%s", columns, g.quote(constraint.ReferenceTable), g.quote(constraint.ReferenceColumn), foreignKeyActions(constraint.Relation))
+	}
+
+	return fmt.Sprintf("UNIQUE (%s)", columns)
+}
+
+// createIndexes returns the CREATE INDEX statements of a model, indexes are
+// not part of a sqlite table.
+func (g *Sqlite3Generator) createIndexes(model *ast.Model, table string) string {
+	_, indexes := tableConstraints(g.ast, model)
+
+	var sb strings.Builder
+	for _, index := range indexes {
+		sb.WriteString(g.createIndex(table, index))
+	}
+
+	return sb.String()
+}
+
+func (g *Sqlite3Generator) createIndex(table string, index *migration.ConstraintChange) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s);\n", g.quote(index.Name), g.quote(table), columnList(index.Columns, g.quote))
+}
+
+// generateJoinTable creates the table of a many-to-many relation, a row links
+// one row of each model and is removed with either of them.
+func (g *Sqlite3Generator) generateJoinTable(table *analyzer.JoinTable, idx int) error {
+	sql, err := g.createJoinTable(table)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "migrations", fmt.Sprintf("%d_%s.sql", idx+1, table.Name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	g.writer = f
+
+	g.writer.Write([]byte(sql))
+	g.writer.Write([]byte("\n"))
 
 	return nil
 }
 
-func (g *Sqlite3Generator) generateItem(item *ast.Declaration, isLast bool) error {
+func (g *Sqlite3Generator) createJoinTable(table *analyzer.JoinTable) (string, error) {
+	lines := []string{}
+	keys := []string{}
+	for _, end := range []*analyzer.RelationEnd{table.A, table.B} {
+		id, ok := idField(end.Model)
+		if !ok {
+			return "", fmt.Errorf("model %s has no @id field", end.Model.Name.Identifier)
+		}
+
+		decType, ok := g.typeToSqliteType(id.DeclarationType)
+		if !ok {
+			return "", fmt.Errorf("invalid type %s", id.DeclarationType.Name)
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s %s NOT NULL", g.quote(end.Column), decType))
+		keys = append(keys, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s(%s) ON DELETE CASCADE", g.quote(end.Column), g.quote(decorator.TableName(g.ast, end.Model)), g.quote(decorator.ColumnName(g.ast, id))))
+	}
+
+	lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s, %s)", g.quote(table.A.Column), g.quote(table.B.Column)))
+	lines = append(lines, keys...)
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(g.quote(table.Name))
+	sb.WriteString(" (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	return sb.String(), nil
+}
+
+func (g *Sqlite3Generator) GenerateMigration(m *migration.Migration, cfg *GeneratorConfig, name string) error {
+	g.cfg = cfg
+
+	up, err := g.migrate(m.Changes)
+	if err != nil {
+		return err
+	}
+
+	down, err := g.migrate(m.Down())
+	if err != nil {
+		return err
+	}
+
+	return writeMigration(cfg, name, up, down)
+}
+
+func (g *Sqlite3Generator) migrate(changes []*migration.Change) (string, error) {
+	statements := []string{}
+
+	for _, change := range changes {
+		g.ast = change.To
+
+		sql := ""
+		var err error
+
+		switch change.Type {
+		case migration.ChangeCreateTable:
+			table := decorator.TableName(change.To, change.Model)
+			sql, err = g.createTable(change.Model, table)
+			sql += g.createIndexes(change.Model, table)
+		case migration.ChangeDropTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)))
+		case migration.ChangeRenameTable:
+			sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s;\n", g.quote(decorator.TableName(change.From, change.PrevModel)), g.quote(decorator.TableName(change.To, change.Model)))
+		case migration.ChangeAlterTable:
+			sql, err = g.alterTable(change)
+		case migration.ChangeCreateJoinTable:
+			sql, err = g.createJoinTable(change.JoinTable)
+		case migration.ChangeDropJoinTable:
+			sql = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.quote(change.PrevJoinTable.Name))
+		}
+
+		if err != nil {
+			return "", err
+		}
+		if sql != "" {
+			statements = append(statements, sql)
+		}
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+// alterTable adds and renames columns and indexes in place, any other
+// change rebuilds the table as sqlite can not alter columns or constraints.
+func (g *Sqlite3Generator) alterTable(change *migration.Change) (string, error) {
+	statements := []string{}
+	indexes := []string{}
+	for _, constraint := range change.Constraints {
+		if constraint.Kind != migration.ConstraintIndex {
+			return g.rebuildTable(change)
+		}
+
+		if constraint.Type == migration.ConstraintDrop {
+			statements = append(statements, fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", g.quote(constraint.Name)))
+		} else {
+			indexes = append(indexes, g.createIndex(change.Table, constraint))
+		}
+	}
+
+	for _, column := range change.Columns {
+		switch column.Type {
+		case migration.ColumnAdd:
+			if !g.canAddColumn(column.Field) {
+				return g.rebuildTable(change)
+			}
+
+			line, err := g.generateItem(column.Field)
+			if err != nil {
+				return "", err
+			}
+
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", g.quote(change.Table), strings.TrimSpace(line)))
+		case migration.ColumnRename:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;\n", g.quote(change.Table), g.quote(decorator.ColumnName(change.From, column.PrevField)), g.quote(decorator.ColumnName(g.ast, column.Field))))
+		case migration.ColumnAlter:
+			changed, err := g.isColumnChanged(change, column)
+			if err != nil {
+				return "", err
+			}
+			if changed {
+				return g.rebuildTable(change)
+			}
+		default:
+			return g.rebuildTable(change)
+		}
+	}
+
+	statements = append(statements, indexes...)
+
+	return strings.Join(statements, ""), nil
+}
+
+// canAddColumn tells whether ALTER TABLE ADD COLUMN accepts the column, it
+// can be neither a key nor NOT NULL without a constant default.
+func (g *Sqlite3Generator) canAddColumn(item *ast.Declaration) bool {
+	decorators := decorator.ForField(item)
+	if decorators.Id != nil || decorators.Unique != nil {
+		return false
+	}
+
+	if decorators.Default != nil {
+		return decorators.Default.Function == ""
+	}
+
+	return decorators.Nullable != nil
+}
+
+// isColumnChanged compares the definitions of an altered column, the values
+// of an enum are not part of a sqlite column.
+func (g *Sqlite3Generator) isColumnChanged(change *migration.Change, column *migration.ColumnChange) (bool, error) {
+	line, err := g.generateItem(column.Field)
+	if err != nil {
+		return false, err
+	}
+
+	g.ast = change.From
+	defer func() { g.ast = change.To }()
+
+	prevLine, err := g.generateItem(column.PrevField)
+	if err != nil {
+		return false, err
+	}
+
+	return line != prevLine, nil
+}
+
+// rebuildTable creates the altered table under a temporary name, copies the
+// rows which are kept and replaces the table. Its indexes are dropped with
+// the table and created again.
+func (g *Sqlite3Generator) rebuildTable(change *migration.Change) (string, error) {
+	name := change.Table
+	temporary := name + "_new"
+
+	renamed := map[string]string{}
+	added := map[string]struct{}{}
+	for _, column := range change.Columns {
+		switch column.Type {
+		case migration.ColumnRename:
+			renamed[decorator.ColumnName(g.ast, column.Field)] = decorator.ColumnName(change.From, column.PrevField)
+		case migration.ColumnAdd:
+			added[decorator.ColumnName(g.ast, column.Field)] = struct{}{}
+		}
+	}
+
+	columns := []string{}
+	prevColumns := []string{}
+	for _, item := range change.Model.Items {
+		column := decorator.ColumnName(g.ast, item)
+		if _, ok := added[column]; ok || g.isTypeModel(item.DeclarationType) {
+			continue
+		}
+
+		prevColumn, ok := renamed[column]
+		if !ok {
+			prevColumn = column
+		}
+
+		columns = append(columns, column)
+		prevColumns = append(prevColumns, prevColumn)
+	}
+
+	create, err := g.createTable(change.Model, temporary)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(create)
+	sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;\n", g.quote(temporary), columnList(columns, g.quote), columnList(prevColumns, g.quote), g.quote(name)))
+	sb.WriteString(fmt.Sprintf("DROP TABLE %s;\n", g.quote(name)))
+	sb.WriteString(fmt.Sprintf("ALTER TABLE %s RENAME TO %s;\n", g.quote(temporary), g.quote(name)))
+	sb.WriteString(g.createIndexes(change.Model, name))
+
+	return sb.String(), nil
+}
+
+func (g *Sqlite3Generator) GenerateField(ast *ast.Ast, item *ast.Declaration) (string, error) {
+	g.ast = ast
+
+	column, err := g.generateItem(item)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(column), nil
+}
+
+func (g *Sqlite3Generator) generateItem(item *ast.Declaration) (string, error) {
 	decType, ok := g.typeToSqliteType(item.DeclarationType)
 	if !ok {
 		if g.isTypeModel(item.DeclarationType) {
-			return nil
+			return "", nil
 		} else {
-			return fmt.Errorf("invalid type %s", item.DeclarationType.Name)
+			return "", fmt.Errorf("invalid type %s", item.DeclarationType.Name)
 		}
 	}
 
-	g.writer.Write([]byte("  "))
-	g.writer.Write([]byte(item.Identifier.Identifier))
-	g.writer.Write([]byte(" "))
-	g.writer.Write(decType)
+	var sb strings.Builder
+	decorators := decorator.ForField(item)
+
+	sb.WriteString("  ")
+	sb.WriteString(g.quote(decorator.ColumnName(g.ast, item)))
+	sb.WriteString(" ")
+	sb.Write(decType)
+
+	if decorators.Nullable == nil {
+		sb.WriteString(" NOT NULL")
+	}
 
-	if _, ok := g.getDecorator("id", item.Decorators); ok {
-		g.writer.Write([]byte(" PRIMARY KEY"))
+	autoincrement := false
+	if decorators.Default != nil {
+		switch decorators.Default.Function {
+		case "":
+			sb.WriteString(" DEFAULT ")
+			sb.WriteString(sqlLiteral(decorators.Default.Value, "1", "0"))
+		case decorator.FunctionNow:
+			sb.WriteString(" DEFAULT ")
+			sb.WriteString(sqlite3Now)
+		case decorator.FunctionUuid:
+			sb.WriteString(" DEFAULT ")
+			sb.WriteString(sqlite3Uuid)
+		case decorator.FunctionAutoincrement:
+			if decorators.Id == nil {
+				return "", fmt.Errorf("autoincrement() of %s requires @id in sqlite3", item.Identifier.Identifier)
+			}
+			autoincrement = true
+		}
 	}
 
-	if !isLast {
-		g.writer.Write([]byte(","))
+	if decorators.Unique != nil {
+		sb.WriteString(" UNIQUE")
 	}
-	g.writer.Write([]byte("\n"))
 
-	return nil
+	if decorators.Id != nil {
+		sb.WriteString(" PRIMARY KEY")
+	}
+
+	if autoincrement {
+		sb.WriteString(" AUTOINCREMENT")
+	}
+
+	return sb.String(), nil
+}
+
+func (g *Sqlite3Generator) quote(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
 }
 
 func (g *Sqlite3Generator) typeToSqliteType(decType *ast.DeclarationType) ([]byte, bool) {
@@ -172,13 +539,3 @@ func (g *Sqlite3Generator) isTypeModel(decType *ast.DeclarationType) bool {
 
 	return false
 }
-
-func (g *Sqlite3Generator) getDecorator(name string, decorators []*ast.Decorator) (*ast.Decorator, bool) {
-	for _, dec := range decorators {
-		if dec.Name.Identifier == name {
-			return dec, true
-		}
-	}
-
-	return nil, false
-}
@@ -1,11 +1,17 @@
 package generator_test
 
 import (
-	"bytes"
+	"database/sql"
+	"os"
+	"path"
 	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/gophoria/gophoria/pkg/generator"
 	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/migration"
 	"github.com/gophoria/gophoria/pkg/parser"
 )
 
@@ -34,26 +40,112 @@ model Post {
   title     string
   content   string  @nullable
   public    bool    @default(false)
+  views     int     @default(0)
+  slug      string  @unique
+  createdAt DateTime @default(now())
   author    User    @relation(field: authorId, reference: id)
   authorId  int
+
+  @@unique([title, authorId])
+}`
+
+	expected := map[string]string{
+		"1_User.sql": `CREATE TABLE IF NOT EXISTS "User" (
+  "id" TEXT NOT NULL DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))) PRIMARY KEY,
+  "name" TEXT NOT NULL,
+  "surname" TEXT NOT NULL,
+  "role" TEXT NOT NULL
+);
+
+`,
+		"2_Post.sql": `CREATE TABLE IF NOT EXISTS "Post" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "title" TEXT NOT NULL,
+  "content" TEXT,
+  "public" INTEGER NOT NULL DEFAULT 0,
+  "views" INTEGER NOT NULL DEFAULT 0,
+  "slug" TEXT NOT NULL UNIQUE,
+  "createdAt" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+  "authorId" INTEGER NOT NULL,
+  UNIQUE ("title", "authorId"),
+  FOREIGN KEY ("authorId") REFERENCES "User"("id")
+);
+
+`,
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	generator := generator.NewSqlite3Generator()
+
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestSqlite3GeneratorRelationOrder(t *testing.T) {
+	input := `
+model Comment {
+  id      int     @id
+  post    Post    @relation(field: postId, reference: id, onDelete: Cascade)
+  postId  int
+  author  User    @relation(field: authorId, reference: id, onDelete: SetNull, onUpdate: Restrict)
+  authorId int    @nullable
+}
+
+model Post {
+  id      int     @id
+  author  User    @relation(field: authorId, reference: id)
+  authorId int
+}
+
+model User {
+  id      int     @id
 }`
 
-	expected := `CREATE TABLE IF NOT EXISTS User (
-  id TEXT PRIMARY KEY
-  name TEXT
-  surname TEXT
-  role TEXT
+	expected := map[string]string{
+		"1_User.sql": `CREATE TABLE IF NOT EXISTS "User" (
+  "id" INTEGER NOT NULL PRIMARY KEY
+);
+
+`,
+		"2_Post.sql": `CREATE TABLE IF NOT EXISTS "Post" (
+  "id" INTEGER NOT NULL PRIMARY KEY,
+  "authorId" INTEGER NOT NULL,
+  FOREIGN KEY ("authorId") REFERENCES "User"("id")
 );
 
-CREATE TABLE IF NOT EXISTS Post (
-  id INTEGER PRIMARY KEY
-  title TEXT
-  content TEXT
-  public INTEGER
-  authorId INTEGER
+`,
+		"3_Comment.sql": `CREATE TABLE IF NOT EXISTS "Comment" (
+  "id" INTEGER NOT NULL PRIMARY KEY,
+  "postId" INTEGER NOT NULL,
+  "authorId" INTEGER,
+  FOREIGN KEY ("postId") REFERENCES "Post"("id") ON DELETE CASCADE,
+  FOREIGN KEY ("authorId") REFERENCES "User"("id") ON DELETE SET NULL ON UPDATE RESTRICT
 );
 
-`
+`,
+	}
 
 	lexer := lexer.NewLexer(input)
 	parser := parser.NewParser(lexer)
@@ -63,15 +155,251 @@ CREATE TABLE IF NOT EXISTS Post (
 		t.Fatalf("parser error: %s", err.Error())
 	}
 
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
 	generator := generator.NewSqlite3Generator()
 
-	var buffer bytes.Buffer
-	err = generator.GenerateAll(ast, nil)
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestSqlite3GeneratorManyToMany(t *testing.T) {
+	input := `
+model Post {
+  id      int     @id
+  tags    Tag[]
+}
+
+model Tag {
+  id      string  @id
+  posts   Post[]
+}`
+
+	expected := map[string]string{
+		"3__PostToTag.sql": `CREATE TABLE IF NOT EXISTS "_PostToTag" (
+  "postId" INTEGER NOT NULL,
+  "tagId" TEXT NOT NULL,
+  PRIMARY KEY ("postId", "tagId"),
+  FOREIGN KEY ("postId") REFERENCES "Post"("id") ON DELETE CASCADE,
+  FOREIGN KEY ("tagId") REFERENCES "Tag"("id") ON DELETE CASCADE
+);
+
+`,
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	generator := generator.NewSqlite3Generator()
+
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestSqlite3GeneratorMigration(t *testing.T) {
+	from := `
+model User {
+  id      int     @id
+  name    string
+}
+
+model Post {
+  id      int     @id
+  title   string
+  author  User    @relation(field: authorId, reference: id)
+  authorId int
+}`
+
+	to := `
+model User {
+  id       int     @id
+  fullName string  @renamedFrom("name")
+  age      int     @nullable
+}
+
+model Post {
+  id      int     @id
+  title   string  @unique
+  author  User    @relation(field: authorId, reference: id)
+  authorId int
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `ALTER TABLE "User" RENAME COLUMN "name" TO "fullName";
+ALTER TABLE "User" ADD COLUMN "age" INTEGER;
+
+CREATE TABLE IF NOT EXISTS "Post_new" (
+  "id" INTEGER NOT NULL PRIMARY KEY,
+  "title" TEXT NOT NULL UNIQUE,
+  "authorId" INTEGER NOT NULL,
+  FOREIGN KEY ("authorId") REFERENCES "User"("id")
+);
+INSERT INTO "Post_new" ("id", "title", "authorId") SELECT "id", "title", "authorId" FROM "Post";
+DROP TABLE "Post";
+ALTER TABLE "Post_new" RENAME TO "Post";
+`,
+		"1_update.down.sql": `CREATE TABLE IF NOT EXISTS "Post_new" (
+  "id" INTEGER NOT NULL PRIMARY KEY,
+  "title" TEXT NOT NULL,
+  "authorId" INTEGER NOT NULL,
+  FOREIGN KEY ("authorId") REFERENCES "User"("id")
+);
+INSERT INTO "Post_new" ("id", "title", "authorId") SELECT "id", "title", "authorId" FROM "Post";
+DROP TABLE "Post";
+ALTER TABLE "Post_new" RENAME TO "Post";
+
+CREATE TABLE IF NOT EXISTS "User_new" (
+  "id" INTEGER NOT NULL PRIMARY KEY,
+  "name" TEXT NOT NULL
+);
+INSERT INTO "User_new" ("id", "name") SELECT "id", "fullName" FROM "User";
+DROP TABLE "User";
+ALTER TABLE "User_new" RENAME TO "User";
+`,
+	}
+
+	generateMigration(t, generator.NewSqlite3Generator(), from, to, expected)
+}
+
+func generateMigration(t *testing.T, gen generator.MigrationGenerator, from string, to string, expected map[string]string) {
+	fromAst, err := parser.NewParser(lexer.NewLexer(from)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	toAst, err := parser.NewParser(lexer.NewLexer(to)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+
+	err = gen.GenerateMigration(migration.Diff(fromAst, toAst), cfg, "1_update")
 	if err != nil {
 		t.Fatalf("generator error: %s", err.Error())
 	}
 
-	if buffer.String() != expected {
-		t.Fatalf("Generator output is not correct")
+	for name, exp := range expected {
+		data, err := os.ReadFile(path.Join(workingDir, "migrations", name))
+		if err != nil {
+			t.Fatalf("unable to read %s: %s", name, err.Error())
+		}
+
+		if string(data) != exp {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, data)
+		}
+	}
+}
+
+func TestSqlite3GeneratorIndexMigration(t *testing.T) {
+	from := `
+model Post {
+  id      int     @id
+  title   string
+}`
+
+	to := `
+model Post {
+  id      int     @id
+  title   string
+  views   int     @default(0)
+
+  @@index([title, views])
+}`
+
+	expected := map[string]string{
+		"1_update.up.sql": `ALTER TABLE "Post" ADD COLUMN "views" INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "Post_title_views_idx" ON "Post" ("title", "views");
+`,
+	}
+
+	generateMigration(t, generator.NewSqlite3Generator(), from, to, expected)
+}
+
+func TestSqlite3GeneratorNowDefault(t *testing.T) {
+	input := `
+model Post {
+  id        int      @id
+  createdAt DateTime @default(now())
+}`
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+
+	err = generator.NewSqlite3Generator().GenerateMigration(migration.Diff(nil, ast), cfg, "1_init")
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	up, err := os.ReadFile(path.Join(workingDir, "migrations", "1_init.up.sql"))
+	if err != nil {
+		t.Fatalf("unable to read migration: %s", err.Error())
+	}
+
+	db, err := sql.Open("sqlite3", path.Join(workingDir, "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	_, err = db.Exec(string(up))
+	if err != nil {
+		t.Fatalf("unable to run migration: %s", err.Error())
+	}
+
+	_, err = db.Exec(`INSERT INTO "Post" ("id") VALUES (1)`)
+	if err != nil {
+		t.Fatalf("unable to insert row: %s", err.Error())
+	}
+
+	// the generated DateTime scans RFC 3339 text
+	var createdAt string
+	err = db.QueryRow(`SELECT "createdAt" FROM "Post" WHERE "id" = 1`).Scan(&createdAt)
+	if err != nil {
+		t.Fatalf("unable to read row: %s", err.Error())
+	}
+
+	_, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		t.Fatalf("default is not RFC 3339: %s", err.Error())
 	}
 }
@@ -2,23 +2,55 @@ package generator
 
 import (
 	"fmt"
+	"go/token"
 	"io"
 	"os"
 	"path"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gophoria/gophoria/internal/code"
 	"github.com/gophoria/gophoria/internal/utils"
+	"github.com/gophoria/gophoria/pkg/analyzer"
 	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
 )
 
+// Nullable fields are generated as the types of database/sql by default,
+// the nullable option of the db block switches to sql.Null[T] or pointers.
+const (
+	NullableSql     = "sql"
+	NullableGeneric = "generic"
+	NullablePointer = "pointer"
+)
+
+var sqlNullTypes = map[ast.VariableType]string{
+	ast.VariableTypeInt:      "sql.NullInt64",
+	ast.VariableTypeReal:     "sql.NullFloat64",
+	ast.VariableTypeBool:     "sql.NullBool",
+	ast.VariableTypeString:   "sql.NullString",
+	ast.VariableTypeDateTime: "NullDateTime",
+}
+
+// sqlxGeneratedValues generate the values of the fields defaulting to uuid()
+// and ulid() before a row is inserted.
+var sqlxGeneratedValues = map[string]string{
+	decorator.FunctionUuid: "uuid.NewString()",
+	decorator.FunctionUlid: "ulid.Make().String()",
+}
+
 type SqlxGenerator struct {
-	ast    *ast.Ast
-	writer io.Writer
-	cfg    *GeneratorConfig
+	ast      *ast.Ast
+	writer   io.Writer
+	cfg      *GeneratorConfig
+	provider string
+	nullable string
+	context  bool
 }
 
 func init() {
-	RegisterGenerator("sqlx", NewSqlxGenerator())
+	RegisterGenerator("sqlx", func() Generator { return NewSqlxGenerator() })
 }
 
 func NewSqlxGenerator() *SqlxGenerator {
@@ -31,7 +63,12 @@ func (g *SqlxGenerator) GenerateAll(ast *ast.Ast, cfg *GeneratorConfig) error {
 	g.ast = ast
 	g.cfg = cfg
 
-	err := os.MkdirAll(path.Join(g.cfg.WorkingDir, "db"), 0755)
+	err := g.configure()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(path.Join(g.cfg.WorkingDir, "db"), 0755)
 	if err != nil {
 		return err
 	}
@@ -61,14 +98,19 @@ func (g *SqlxGenerator) GenerateAll(ast *ast.Ast, cfg *GeneratorConfig) error {
 		}
 	}
 
-	return nil
+	return g.generateQueries(ast)
 }
 
 func (g *SqlxGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name string) error {
 	g.ast = ast
 	g.cfg = cfg
 
-	err := os.MkdirAll(path.Join(g.cfg.WorkingDir, "db"), 0755)
+	err := g.configure()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(path.Join(g.cfg.WorkingDir, "db"), 0755)
 	if err != nil {
 		return err
 	}
@@ -79,6 +121,10 @@ func (g *SqlxGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name string
 		return g.generateDateTime(ast, g.writer)
 	}
 
+	if name == "Queries" {
+		return g.generateQueries(ast)
+	}
+
 	for _, enum := range ast.Enums {
 		if enum.Name.Identifier == name {
 			isExist = true
@@ -118,16 +164,49 @@ func (g *SqlxGenerator) Generate(ast *ast.Ast, cfg *GeneratorConfig, name string
 	return nil
 }
 
+// configure reads the options of the db block.
+func (g *SqlxGenerator) configure() error {
+	g.provider = ""
+	g.nullable = NullableSql
+	g.context = true
+
+	if item, ok := g.ast.ConfigItem("db", "provider"); ok {
+		g.provider = item.Value.Value
+	}
+
+	// context = false keeps the store methods without a context, the
+	// analyzer reports values of the options which are not valid
+	if item, ok := g.ast.ConfigItem("db", "context"); ok && item.Value.Type == ast.ValueTypeBool {
+		g.context = item.Value.Value == "true"
+	}
+
+	if item, ok := g.ast.ConfigItem("db", "nullable"); ok && slices.Contains(analyzer.Nullables, item.Value.Value) {
+		g.nullable = item.Value.Value
+	}
+
+	return nil
+}
+
 func (g *SqlxGenerator) generateModel(model *ast.Model) error {
 	g.writer.Write([]byte("package db\n\n"))
 
-	g.writer.Write([]byte(`import (
-  "github.com/jmoiron/sqlx"
-	"github.com/google/uuid"
-)
-
-`))
+	g.writer.Write([]byte("import (\n"))
+	if g.context {
+		g.writer.Write([]byte("\t\"context\"\n"))
+	}
+	if g.usesDatabaseSql(model) {
+		g.writer.Write([]byte("\t\"database/sql\"\n\n"))
+	}
+	g.writer.Write([]byte("\t\"github.com/jmoiron/sqlx\"\n"))
+	if g.usesGeneratedValue(model, decorator.FunctionUuid) {
+		g.writer.Write([]byte("\t\"github.com/google/uuid\"\n"))
+	}
+	if g.usesGeneratedValue(model, decorator.FunctionUlid) {
+		g.writer.Write([]byte("\t\"github.com/oklog/ulid/v2\"\n"))
+	}
+	g.writer.Write([]byte(")\n\n"))
 
+	g.generateDoc(model.Doc, "")
 	g.writer.Write([]byte("type "))
 	g.writer.Write([]byte(model.Name.Identifier))
 	g.writer.Write([]byte(" struct {\n"))
@@ -144,43 +223,114 @@ func (g *SqlxGenerator) generateModel(model *ast.Model) error {
 	return nil
 }
 
+func (g *SqlxGenerator) GenerateField(ast *ast.Ast, item *ast.Declaration) (string, error) {
+	g.ast = ast
+
+	err := g.configure()
+	if err != nil {
+		return "", err
+	}
+
+	goType, err := g.goType(item)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s `db:\"%s\"`", utils.Capitalize(item.Identifier.Identifier), goType, decorator.ColumnName(g.ast, item)), nil
+}
+
 func (g *SqlxGenerator) generateModelItem(item *ast.Declaration) error {
+	g.generateDoc(item.Doc, "  ")
 	g.writer.Write([]byte("  "))
 	g.writer.Write([]byte(utils.Capitalize(item.Identifier.Identifier)))
 	g.writer.Write([]byte(" "))
 
+	goType, err := g.goType(item)
+	if err != nil {
+		return err
+	}
+	g.writer.Write([]byte(goType))
+
+	g.writer.Write([]byte(" `db:\""))
+	g.writer.Write([]byte(decorator.ColumnName(g.ast, item)))
+	g.writer.Write([]byte("\"`"))
+	g.writer.Write([]byte("\n"))
+
+	return nil
+}
+
+// goType returns the Go type of the field. Nullable columns get a type that
+// can hold NULL, relations are pointers anyway.
+func (g *SqlxGenerator) goType(item *ast.Declaration) (string, error) {
+	if g.isTypeModel(item.DeclarationType) {
+		if item.DeclarationType.IsArray {
+			return "[]*" + item.DeclarationType.Name, nil
+		}
+		return "*" + item.DeclarationType.Name, nil
+	}
+
+	goType, err := g.valueType(item)
+	if err != nil {
+		return "", err
+	}
+
+	if decorator.ForField(item).Nullable == nil {
+		return goType, nil
+	}
+
+	switch g.nullable {
+	case NullablePointer:
+		return "*" + goType, nil
+	case NullableSql:
+		if nullType, ok := sqlNullTypes[item.DeclarationType.Type]; ok {
+			return nullType, nil
+		}
+		// enums have no database/sql type, sql.Null[T] needs Go 1.22
+		return "Null" + goType, nil
+	}
+
+	return "sql.Null[" + goType + "]", nil
+}
+
+// valueType returns the Go type of the values of a column, which are
+// compared in predicates whether the column is nullable or not.
+func (g *SqlxGenerator) valueType(item *ast.Declaration) (string, error) {
+	goType := ""
+
 	switch item.DeclarationType.Type {
 	case ast.VariableTypeInt:
-		g.writer.Write([]byte("int"))
+		goType = "int"
 	case ast.VariableTypeReal:
-		g.writer.Write([]byte("float64"))
+		goType = "float64"
 	case ast.VariableTypeBool:
-		g.writer.Write([]byte("bool"))
+		goType = "bool"
 	case ast.VariableTypeString:
-		g.writer.Write([]byte("string"))
+		goType = "string"
 	case ast.VariableTypeDateTime:
-		g.writer.Write([]byte("DateTime"))
+		goType = "DateTime"
 	case ast.VariableTypeObject:
-		if g.isTypeModel(item.DeclarationType) {
-			if item.DeclarationType.IsArray {
-				g.writer.Write([]byte("[]"))
-			}
-			g.writer.Write([]byte("*"))
-			g.writer.Write([]byte(item.DeclarationType.Name))
-		} else if g.isTypeEnum(item.DeclarationType) {
-			g.writer.Write([]byte(item.DeclarationType.Name))
-		} else {
-			return fmt.Errorf("not supported type (%s) for item %s", item.DeclarationType.Name, item.Identifier.Identifier)
+		if !g.isTypeEnum(item.DeclarationType) {
+			return "", fmt.Errorf("not supported type (%s) for item %s", item.DeclarationType.Name, item.Identifier.Identifier)
 		}
+		goType = item.DeclarationType.Name
 	default:
-		return fmt.Errorf("not supported type (%s) for item %s", item.DeclarationType.Name, item.Identifier.Identifier)
+		return "", fmt.Errorf("not supported type (%s) for item %s", item.DeclarationType.Name, item.Identifier.Identifier)
 	}
-	g.writer.Write([]byte(" `db:\""))
-	g.writer.Write([]byte(item.Identifier.Identifier))
-	g.writer.Write([]byte("\"`"))
-	g.writer.Write([]byte("\n"))
 
-	return nil
+	return goType, nil
+}
+
+// usesDatabaseSql reports whether a field of the model has a type of the
+// database/sql package.
+func (g *SqlxGenerator) usesDatabaseSql(model *ast.Model) bool {
+	for _, item := range model.Items {
+		goType, err := g.goType(item)
+		if err == nil && strings.HasPrefix(goType, "sql.") {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (g *SqlxGenerator) generateEnum(enum *ast.Enum) error {
@@ -195,24 +345,32 @@ func (g *SqlxGenerator) generateEnum(enum *ast.Enum) error {
 	defer f.Close()
 	g.writer = f
 
+	valueType := enum.Items[0].Value.Type
+	if valueType != ast.ValueTypeInt && valueType != ast.ValueTypeString {
+		return fmt.Errorf("enum %s contains not supported type", enum.Name.Identifier)
+	}
+
 	g.writer.Write([]byte("package db\n\n"))
 
+	nullable := g.isNullableEnum(enum)
+	if nullable {
+		g.writer.Write([]byte("import (\n\t\"database/sql\"\n\t\"database/sql/driver\"\n)\n\n"))
+	}
+
+	g.generateDoc(enum.Doc, "")
 	g.writer.Write([]byte("type "))
 	g.writer.Write([]byte(enum.Name.Identifier))
 
-	valueType := enum.Items[0].Value.Type
-	switch valueType {
-	case ast.ValueTypeInt:
+	if valueType == ast.ValueTypeInt {
 		g.writer.Write([]byte(" int"))
-	case ast.ValueTypeString:
+	} else {
 		g.writer.Write([]byte(" string"))
-	default:
-		return fmt.Errorf("enum %s contains not supported type", enum.Name.Identifier)
 	}
 	g.writer.Write([]byte("\n\n"))
 
 	g.writer.Write([]byte("const (\n"))
 	for _, item := range enum.Items {
+		g.generateDoc(item.Doc, "  ")
 		g.writer.Write([]byte("  "))
 		g.writer.Write([]byte(enum.Name.Identifier))
 		g.writer.Write([]byte(utils.Capitalize(item.Identifier.Identifier)))
@@ -220,217 +378,1273 @@ func (g *SqlxGenerator) generateEnum(enum *ast.Enum) error {
 		g.writer.Write([]byte(enum.Name.Identifier))
 		g.writer.Write([]byte(" = "))
 		if valueType == ast.ValueTypeString {
-			g.writer.Write([]byte("\""))
-		}
-		g.writer.Write([]byte(item.Value.Value))
-		if valueType == ast.ValueTypeString {
-			g.writer.Write([]byte("\""))
+			g.writer.Write([]byte(strconv.Quote(item.Value.Value)))
+		} else {
+			g.writer.Write([]byte(item.Value.Value))
 		}
 		g.writer.Write([]byte("\n"))
 	}
 	g.writer.Write([]byte(")\n\n"))
 
-	return nil
-}
-
-func (g *SqlxGenerator) generateDateTime(ast *ast.Ast, writer io.Writer) error {
-	f, err := os.Create(path.Join(g.cfg.WorkingDir, "db", "DateTime.go"))
-	if err != nil {
-		return err
+	if nullable {
+		g.generateNullEnum(enum)
 	}
-	defer f.Close()
-
-	f.Write(code.DateTime)
 
 	return nil
 }
 
-func (g *SqlxGenerator) isTypeModel(decType *ast.DeclarationType) bool {
-	name := decType.Name
+// isNullableEnum reports whether a nullable field has the Null type of the
+// enum, which is only generated then.
+func (g *SqlxGenerator) isNullableEnum(enum *ast.Enum) bool {
+	if g.nullable != NullableSql {
+		return false
+	}
 
 	for _, model := range g.ast.Models {
-		if model.Name.Identifier == name {
-			return true
+		for _, item := range model.Items {
+			if item.DeclarationType.Name == enum.Name.Identifier && decorator.ForField(item).Nullable != nil {
+				return true
+			}
 		}
 	}
 
 	return false
 }
 
-func (g *SqlxGenerator) isTypeEnum(decType *ast.DeclarationType) bool {
-	name := decType.Name
-
-	for _, enum := range g.ast.Enums {
-		if enum.Name.Identifier == name {
-			return true
-		}
+// generateNullEnum generates the nullable type of an enum, which scans and
+// stores it through the database/sql type of its values.
+func (g *SqlxGenerator) generateNullEnum(enum *ast.Enum) {
+	nullType, field, value := "sql.NullString", "String", "string"
+	if enum.Items[0].Value.Type == ast.ValueTypeInt {
+		nullType, field, value = "sql.NullInt64", "Int64", "int64"
 	}
 
-	return false
+	g.writer.Write([]byte(fmt.Sprintf(`// Null%[1]s is a %[1]s which may be NULL.
+type Null%[1]s struct {
+	%[1]s %[1]s
+	Valid bool
 }
 
-func (g *SqlxGenerator) generateStore(model *ast.Model) error {
-	g.writer.Write([]byte(fmt.Sprintf(`type %sStore struct {
-  conn *sqlx.DB
+func (n Null%[1]s) Value() (driver.Value, error) {
+	return %[2]s{%[3]s: %[4]s(n.%[1]s), Valid: n.Valid}.Value()
 }
 
-`, model.Name.Identifier)))
+func (n *Null%[1]s) Scan(src interface{}) error {
+	var value %[2]s
+	err := value.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	n.%[1]s, n.Valid = %[1]s(value.%[3]s), value.Valid
 
-	g.generateStoreNewMethod(model)
-	g.generateStoreInsertMethod(model)
-	g.generateStoreUpdateMethod(model)
-	g.generateStoreDeleteMethod(model)
-	g.generateStoreGetAllMethod(model)
-	g.generateStoreGetByIdMethod(model)
 	return nil
 }
+`, enum.Name.Identifier, nullType, field, value)))
+}
 
-func (g *SqlxGenerator) generateStoreNewMethod(model *ast.Model) error {
-	code := fmt.Sprintf(`func New%[1]sStore(conn *sqlx.DB) *%[1]sStore {
-	return &%[1]sStore{conn: conn}
+func (g *SqlxGenerator) generateDoc(doc []*ast.Comment, indent string) {
+	for _, comment := range doc {
+		g.writer.Write([]byte(indent))
+		g.writer.Write([]byte("//"))
+		if comment.Text != "" {
+			g.writer.Write([]byte(" "))
+			g.writer.Write([]byte(comment.Text))
+		}
+		g.writer.Write([]byte("\n"))
+	}
 }
 
-`, model.Name.Identifier)
+func (g *SqlxGenerator) generateDateTime(ast *ast.Ast, writer io.Writer) error {
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "db", "DateTime.go"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	f.Write(code.DateTime)
 
-	g.writer.Write([]byte(code))
 	return nil
 }
 
-func (g *SqlxGenerator) generateStoreInsertMethod(model *ast.Model) error {
-	query := ""
-	queryVar := ""
+// generateQueries writes the DBTX interface the stores run their queries on
+// and Queries, which holds a store of every model and runs them in a
+// transaction, along with the predicates of the finders.
+func (g *SqlxGenerator) generateQueries(ast *ast.Ast) error {
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "db", "Queries.go"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	for _, item := range model.Items {
-		if item.DeclarationType.Type == ast.VariableTypeObject {
-			if !g.isTypeEnum(item.DeclarationType) {
-				continue
-			}
+	var fields, stores strings.Builder
+	for _, model := range ast.Models {
+		fields.WriteString(fmt.Sprintf("\t%[1]s *%[1]sStore\n", model.Name.Identifier))
+		stores.WriteString(fmt.Sprintf("\t\t%[1]s: New%[1]sStore(conn),\n", model.Name.Identifier))
+	}
+
+	f.Write([]byte(fmt.Sprintf(`package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is satisfied by both *sqlx.DB and *sqlx.Tx.
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	NamedExec(query string, arg any) (sql.Result, error)
+	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
+	Get(dest any, query string, args ...any) error
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	Select(dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// Queries holds a store of every model sharing one connection.
+type Queries struct {
+	conn DBTX
+%[1]s}
+
+func NewQueries(conn DBTX) *Queries {
+	return &Queries{
+		conn: conn,
+%[2]s	}
+}
+
+// WithTx returns the stores running their queries in the transaction.
+func (q *Queries) WithTx(tx *sqlx.Tx) *Queries {
+	return NewQueries(tx)
+}
+
+// RunInTx runs fn in a transaction, which is committed if fn returns nil
+// and rolled back otherwise. Queries which are already running in a
+// transaction pass themselves to fn, other connections can not start one.
+func (q *Queries) RunInTx(ctx context.Context, fn func(q *Queries) error) error {
+	switch conn := q.conn.(type) {
+	case *sqlx.DB:
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
 		}
+		defer tx.Rollback()
 
-		if query != "" {
-			query += ",\n"
-			queryVar += ",\n:"
+		err = fn(q.WithTx(tx))
+		if err != nil {
+			return err
 		}
 
-		query += "\t\t" + item.Identifier.Identifier
-		queryVar += "\t\t:" + item.Identifier.Identifier
+		return tx.Commit()
+	case *sqlx.Tx:
+		return fn(q)
+	default:
+		return fmt.Errorf("unable to run a transaction on %%T", conn)
 	}
+}
 
-	g.writer.Write([]byte(fmt.Sprintf("func (s *%[1]sStore) Insert(m *%[1]s) error {\n", model.Name.Identifier)))
-	g.writer.Write([]byte("\tif m.Id == \"\" {\n"))
-	g.writer.Write([]byte("\t\tm.Id = uuid.NewString()\n"))
-	g.writer.Write([]byte("\t}\n\n"))
+// RunInTx runs fn in a transaction of conn, see Queries.RunInTx.
+func RunInTx(ctx context.Context, conn *sqlx.DB, fn func(q *Queries) error) error {
+	return NewQueries(conn).RunInTx(ctx, fn)
+}
+`, fields.String(), stores.String())))
 
-	g.writer.Write([]byte(fmt.Sprintf("\t_, err := s.conn.NamedExec(`INSERT INTO %[1]s (\n", model.Name.Identifier)))
-	g.writer.Write([]byte(query))
-	g.writer.Write([]byte("\n\t) VALUES (\n"))
-	g.writer.Write([]byte(query))
-	g.writer.Write([]byte("\n\t)`, m)\n\n"))
+	return g.generateWhere()
+}
 
-	g.writer.Write([]byte("\tif err != nil {\n"))
-	g.writer.Write([]byte("\t\treturn err\n"))
-	g.writer.Write([]byte("\t}\n\n"))
+// sqlxNoLimit is the LIMIT clause of a query with an OFFSET but no limit,
+// which sqlite and mysql require.
+var sqlxNoLimit = map[string]string{
+	"sqlite3": " LIMIT -1",
+	"mysql":   " LIMIT 18446744073709551615",
+}
 
-	g.writer.Write([]byte("\treturn nil\n"))
-	g.writer.Write([]byte("}\n\n"))
+// sqlxDefaultValues inserts a row of defaults, mysql has no DEFAULT VALUES.
+func sqlxDefaultValues(provider string) string {
+	if provider == "mysql" {
+		return " () VALUES ()"
+	}
 
-	return nil
+	return " DEFAULT VALUES"
 }
 
-func (g *SqlxGenerator) generateStoreUpdateMethod(model *ast.Model) error {
-	query := ""
+// generateWhere writes the predicates and orderings of the finders, which
+// bind their parameters for the provider of the db block.
+func (g *SqlxGenerator) generateWhere() error {
+	f, err := os.Create(path.Join(g.cfg.WorkingDir, "db", "Where.go"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	for _, item := range model.Items {
-		if item.DeclarationType.Type == ast.VariableTypeObject {
-			if !g.isTypeEnum(item.DeclarationType) {
-				continue
-			}
-		}
+	bindType := "sqlx.QUESTION"
+	if g.provider == "postgres" {
+		bindType = "sqlx.DOLLAR"
+	}
 
-		if query != "" {
-			query += ",\n"
+	// DateTime values are stored as text by sqlite, the other databases get
+	// them as time.Time to compare the fractional seconds Value cuts.
+	timeImport := ""
+	keysetTimes := ""
+	if g.provider == "postgres" || g.provider == "mysql" {
+		timeImport = "\t\"time\"\n"
+		keysetTimes = `	for i, value := range values {
+		if t, ok := value.(interface{ Time() time.Time }); ok {
+			values[i] = t.Time()
 		}
+	}
 
-		query += "\t\t" + item.Identifier.Identifier + "=" + item.Identifier.Identifier
+`
 	}
 
-	g.writer.Write([]byte(fmt.Sprintf("func (s *%[1]sStore) Update(m *%[1]s) error {\n", model.Name.Identifier)))
-	g.writer.Write([]byte(fmt.Sprintf("\t_, err := s.conn.NamedExec(`UPDATE %[1]s SET\n", model.Name.Identifier)))
-	g.writer.Write([]byte(query))
-	g.writer.Write([]byte("\n\tWHERE id=:id`, m)\n\n"))
-	g.writer.Write([]byte("\tif err != nil {\n"))
-	g.writer.Write([]byte("\t\treturn err\n"))
-	g.writer.Write([]byte("\t}\n\n"))
-	g.writer.Write([]byte("\treturn nil\n"))
-	g.writer.Write([]byte("}\n\n"))
+	f.Write([]byte(fmt.Sprintf(`package db
 
-	return nil
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+%[3]s
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Predicate is a condition of a WHERE clause.
+type Predicate struct {
+	sql  string
+	args []any
 }
 
-func (g *SqlxGenerator) generateStoreSaveMethod(model *ast.Model) error {
-	code := fmt.Sprintf(`func (s *%[1]sStore) Save(p *%[1]s) error {
-  if p.Id == "" {
-    return p.Insert()
-  } else {
-    return p.Update()
-  }
+// Where holds the predicates rows have to match, all of them.
+type Where []Predicate
+
+// Ordering sorts rows by a column.
+type Ordering struct {
+	column string
+	desc   bool
 }
-`, model.Name.Identifier)
 
-	g.writer.Write([]byte(code))
-	return nil
+// OrderBy holds the orderings of rows, the first one sorts first.
+type OrderBy []Ordering
+
+// Column refers to a column holding values of type T.
+type Column[T any] struct {
+	name string
 }
 
-func (g *SqlxGenerator) generateStoreDeleteMethod(model *ast.Model) error {
-	code := fmt.Sprintf(`func (s *%[1]sStore) Delete(m *%[1]s) error {
-	query := "DELETE FROM %[1]s WHERE id=:id"
+func (c Column[T]) Eq(value T) Predicate {
+	return c.compare("=", value)
+}
 
-	_, err := s.conn.NamedExec(query, m)
-  if err != nil {
-    return err
-  }
+func (c Column[T]) Ne(value T) Predicate {
+	return c.compare("<>", value)
+}
 
-  return nil
+func (c Column[T]) Gt(value T) Predicate {
+	return c.compare(">", value)
 }
 
-`, model.Name.Identifier)
+func (c Column[T]) Gte(value T) Predicate {
+	return c.compare(">=", value)
+}
 
-	g.writer.Write([]byte(code))
-	return nil
+func (c Column[T]) Lt(value T) Predicate {
+	return c.compare("<", value)
 }
 
-func (g *SqlxGenerator) generateStoreGetAllMethod(model *ast.Model) error {
-	code := fmt.Sprintf(`func (s *%[1]sStore) GetAll() ([]*%[1]s, error) {
-	var result []*%[1]s
-	query := "SELECT * FROM %[1]s"
+func (c Column[T]) Lte(value T) Predicate {
+	return c.compare("<=", value)
+}
 
-	err := s.conn.Select(&result, query)
-  if err != nil {
-    return result, err
-  }
+func (c Column[T]) Like(pattern string) Predicate {
+	return Predicate{sql: c.name + " LIKE ?", args: []any{pattern}}
+}
 
-	return result, nil
+// In matches any of the values, no row for no values.
+func (c Column[T]) In(values ...T) Predicate {
+	if len(values) == 0 {
+		return Predicate{sql: "1=0"}
+	}
+
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	return Predicate{sql: c.name + " IN (?" + strings.Repeat(", ?", len(values)-1) + ")", args: args}
 }
 
-`, model.Name.Identifier)
+func (c Column[T]) IsNull() Predicate {
+	return Predicate{sql: c.name + " IS NULL"}
+}
 
-	g.writer.Write([]byte(code))
-	return nil
+func (c Column[T]) IsNotNull() Predicate {
+	return Predicate{sql: c.name + " IS NOT NULL"}
 }
 
-func (g *SqlxGenerator) generateStoreGetByIdMethod(model *ast.Model) error {
-	code := fmt.Sprintf(`func (s *%[1]sStore) GetById(id string) (*%[1]s, error) {
-	var result %[1]s
-	query := "SELECT * FROM %[1]s WHERE id=?"
+func (c Column[T]) Asc() Ordering {
+	return Ordering{column: c.name}
+}
 
-	err := s.conn.Get(&result, query, id)
-  if err != nil {
-    return nil, err
-  }
+func (c Column[T]) Desc() Ordering {
+	return Ordering{column: c.name, desc: true}
+}
 
-	return  &result, nil
+func (c Column[T]) compare(operator string, value T) Predicate {
+	return Predicate{sql: c.name + " " + operator + " ?", args: []any{value}}
 }
-`, model.Name.Identifier)
+
+// And matches rows matching all of the predicates.
+func And(predicates ...Predicate) Predicate {
+	return join(" AND ", "1=1", predicates)
+}
+
+// Or matches rows matching any of the predicates.
+func Or(predicates ...Predicate) Predicate {
+	return join(" OR ", "1=0", predicates)
+}
+
+func Not(predicate Predicate) Predicate {
+	return Predicate{sql: "NOT (" + predicate.sql + ")", args: predicate.args}
+}
+
+// keyset matches the rows sorted after the values of the columns.
+func keyset(columns []string, values ...any) Predicate {
+%[4]s	return Predicate{sql: "(" + strings.Join(columns, ", ") + ") > (?" + strings.Repeat(", ?", len(values)-1) + ")", args: values}
+}
+
+func join(separator string, empty string, predicates []Predicate) Predicate {
+	if len(predicates) == 0 {
+		return Predicate{sql: empty}
+	}
+
+	conditions := make([]string, len(predicates))
+	args := []any{}
+	for i, predicate := range predicates {
+		conditions[i] = "(" + predicate.sql + ")"
+		args = append(args, predicate.args...)
+	}
+
+	return Predicate{sql: strings.Join(conditions, separator), args: args}
+}
+
+// isZero reports whether a field has its zero value, Insert leaves the
+// column to its default then.
+func isZero[T comparable](value T) bool {
+	var zero T
+	return value == zero
+}
+
+// insertQuery inserts the values of the columns, a row without any takes
+// the default of every column.
+func insertQuery(table string, columns []string, values []string) string {
+	if len(columns) == 0 {
+		return "INSERT INTO " + table + %[5]s
+	}
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")"
+}
+
+// buildQuery appends the WHERE, ORDER BY, LIMIT and OFFSET clauses to a
+// query, a limit of 0 returns every row.
+func buildQuery(query string, where Where, orderBy OrderBy, limit int, offset int) (string, []any) {
+	var sb strings.Builder
+	sb.WriteString(query)
+
+	var args []any
+	if len(where) > 0 {
+		predicate := And(where...)
+		sb.WriteString(" WHERE ")
+		sb.WriteString(predicate.sql)
+		args = predicate.args
+	}
+
+	if len(orderBy) > 0 {
+		orderings := make([]string, len(orderBy))
+		for i, ordering := range orderBy {
+			orderings[i] = ordering.column
+			if ordering.desc {
+				orderings[i] += " DESC"
+			}
+		}
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(orderings, ", "))
+	}
+
+	if limit > 0 {
+		sb.WriteString(fmt.Sprintf(" LIMIT %%d", limit))
+	} else if offset > 0 {
+		sb.WriteString(%[2]s)
+	}
+
+	if offset > 0 {
+		sb.WriteString(fmt.Sprintf(" OFFSET %%d", offset))
+	}
+
+	return sqlx.Rebind(%[1]s, sb.String()), args
+}
+
+// Cursor points after the last row of a page, it is empty for the first
+// page. It encodes the values the rows are sorted by.
+type Cursor string
+
+func newCursor(values ...any) (Cursor, error) {
+	for i, value := range values {
+		if _, ok := value.(json.Marshaler); ok {
+			continue
+		}
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			values[i], err = valuer.Value()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return Cursor(base64.RawURLEncoding.EncodeToString(data)), nil
+}
+
+func (c Cursor) decode(values ...any) error {
+	data, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	var raw []json.RawMessage
+	err = json.Unmarshal(data, &raw)
+	if err != nil || len(raw) != len(values) {
+		return ErrInvalidCursor
+	}
+
+	for i, value := range values {
+		_, unmarshaler := value.(json.Unmarshaler)
+		if scanner, ok := value.(sql.Scanner); ok && !unmarshaler {
+			var src any
+			err = json.Unmarshal(raw[i], &src)
+			if err == nil {
+				err = scanner.Scan(src)
+			}
+		} else {
+			err = json.Unmarshal(raw[i], value)
+		}
+
+		if err != nil {
+			return ErrInvalidCursor
+		}
+	}
+
+	return nil
+}
+`, bindType, strconv.Quote(sqlxNoLimit[g.provider]), timeImport, keysetTimes, strconv.Quote(sqlxDefaultValues(g.provider)))))
+
+	return nil
+}
+
+func (g *SqlxGenerator) isTypeModel(decType *ast.DeclarationType) bool {
+	name := decType.Name
+
+	for _, model := range g.ast.Models {
+		if model.Name.Identifier == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *SqlxGenerator) isTypeEnum(decType *ast.DeclarationType) bool {
+	name := decType.Name
+
+	for _, enum := range g.ast.Enums {
+		if enum.Name.Identifier == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *SqlxGenerator) generateStore(model *ast.Model) error {
+	g.writer.Write([]byte(fmt.Sprintf(`type %sStore struct {
+  conn DBTX
+}
+
+`, model.Name.Identifier)))
+
+	methods := []func(*ast.Model) error{
+		g.generateStoreColumns,
+		g.generateStoreNewMethod,
+		g.generateStoreWithTxMethod,
+		g.generateStoreInsertMethod,
+		g.generateStoreUpdateMethod,
+		g.generateStoreDeleteMethod,
+		g.generateStoreGetAllMethod,
+		g.generateStoreGetByIdMethod,
+		g.generateStoreFinderMethods,
+		g.generateStorePageMethod,
+	}
+
+	for _, method := range methods {
+		err := method(model)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range analyzer.JoinTables(g.ast) {
+		for _, end := range []*analyzer.RelationEnd{table.A, table.B} {
+			if end.Model != model {
+				continue
+			}
+
+			err := g.generateStoreManyToManyMethods(table, end)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreNewMethod(model *ast.Model) error {
+	code := fmt.Sprintf(`func New%[1]sStore(conn DBTX) *%[1]sStore {
+	return &%[1]sStore{conn: conn}
+}
+
+`, model.Name.Identifier)
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreWithTxMethod(model *ast.Model) error {
+	code := fmt.Sprintf(`// WithTx returns a store running its queries in the transaction.
+func (s *%[1]sStore) WithTx(tx *sqlx.Tx) *%[1]sStore {
+	return &%[1]sStore{conn: tx}
+}
+
+`, model.Name.Identifier)
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreInsertMethod(model *ast.Model) error {
+	keys, err := g.keyFields(model)
+	if err != nil {
+		return err
+	}
+
+	// an autoincrement key is left to the database and read back
+	var generated *ast.Declaration
+	if len(keys) == 1 && g.hasDefault(keys[0], decorator.FunctionAutoincrement) {
+		generated = keys[0]
+	}
+
+	query := ""
+	queryVar := ""
+	columns := []string{}
+	values := []string{}
+	defaulted := []*ast.Declaration{}
+
+	for _, item := range model.Items {
+		if !g.isColumn(item) || item == generated {
+			continue
+		}
+
+		column := decorator.ColumnName(g.ast, item)
+		if g.isDefaulted(item) {
+			defaulted = append(defaulted, item)
+			continue
+		}
+
+		if query != "" {
+			query += ",\n"
+			queryVar += ",\n"
+		}
+
+		query += "\t\t" + g.quote(column)
+		queryVar += "\t\t:" + column
+		columns = append(columns, goString(g.quote(column)))
+		values = append(values, strconv.Quote(":"+column))
+	}
+
+	table := g.quote(decorator.TableName(g.ast, model))
+	query = fmt.Sprintf("INSERT INTO %s (\n%s\n\t) VALUES (\n%s\n\t)", table, query, queryVar)
+
+	returning := ""
+	if generated != nil && g.provider == "postgres" {
+		returning = " RETURNING " + g.quote(decorator.ColumnName(g.ast, generated))
+		query += returning
+	}
+
+	if len(defaulted) > 0 {
+		g.writer.Write([]byte("// Insert adds the row, a column with a default is left to the database while\n// its field has the zero value.\n"))
+	}
+	g.writer.Write([]byte(fmt.Sprintf("func (s *%[1]sStore) Insert(%[2]s) error {\n", model.Name.Identifier, g.params("m *"+model.Name.Identifier))))
+
+	for _, item := range model.Items {
+		field := utils.Capitalize(item.Identifier.Identifier)
+		for function, value := range sqlxGeneratedValues {
+			if g.generatesValue(item, function) {
+				g.writer.Write([]byte(fmt.Sprintf("\tif m.%[1]s == \"\" {\n\t\tm.%[1]s = %[2]s\n\t}\n\n", field, value)))
+			}
+		}
+	}
+
+	// the columns with a default are only named if their field is set
+	if len(defaulted) > 0 {
+		g.writer.Write([]byte(fmt.Sprintf("\tcolumns := []string{%s}\n", strings.Join(columns, ", "))))
+		g.writer.Write([]byte(fmt.Sprintf("\tvalues := []string{%s}\n", strings.Join(values, ", "))))
+		for _, item := range defaulted {
+			column := decorator.ColumnName(g.ast, item)
+			g.writer.Write([]byte(fmt.Sprintf("\tif !isZero(m.%s) {\n", utils.Capitalize(item.Identifier.Identifier))))
+			g.writer.Write([]byte(fmt.Sprintf("\t\tcolumns = append(columns, %s)\n", goString(g.quote(column)))))
+			g.writer.Write([]byte(fmt.Sprintf("\t\tvalues = append(values, %s)\n", strconv.Quote(":"+column))))
+			g.writer.Write([]byte("\t}\n"))
+		}
+
+		query = "query"
+		g.writer.Write([]byte(fmt.Sprintf("\tquery := insertQuery(%s, columns, values)", goString(table))))
+		if returning != "" {
+			g.writer.Write([]byte(fmt.Sprintf(" + %s", goString(returning))))
+		}
+		g.writer.Write([]byte("\n\n"))
+	} else {
+		query = goString(query)
+	}
+
+	if generated == nil {
+		g.writer.Write([]byte(fmt.Sprintf("\t_, err := %s\n", g.call("NamedExec", query, "m"))))
+		g.writer.Write([]byte("\tif err != nil {\n"))
+		g.writer.Write([]byte("\t\treturn err\n"))
+		g.writer.Write([]byte("\t}\n\n"))
+		g.writer.Write([]byte("\treturn nil\n"))
+		g.writer.Write([]byte("}\n\n"))
+
+		return nil
+	}
+
+	field := utils.Capitalize(generated.Identifier.Identifier)
+
+	// postgres has no LastInsertId, the key is returned by the query
+	if g.provider == "postgres" {
+		g.writer.Write([]byte(fmt.Sprintf("\tquery, args, err := sqlx.Named(%s, m)\n", query)))
+		g.writer.Write([]byte("\tif err != nil {\n"))
+		g.writer.Write([]byte("\t\treturn err\n"))
+		g.writer.Write([]byte("\t}\n\n"))
+		g.writer.Write([]byte(fmt.Sprintf("\treturn %s\n", g.call("Get", "&m."+field, "sqlx.Rebind(sqlx.DOLLAR, query)", "args..."))))
+		g.writer.Write([]byte("}\n\n"))
+
+		return nil
+	}
+
+	g.writer.Write([]byte(fmt.Sprintf("\tresult, err := %s\n", g.call("NamedExec", query, "m"))))
+	g.writer.Write([]byte("\tif err != nil {\n"))
+	g.writer.Write([]byte("\t\treturn err\n"))
+	g.writer.Write([]byte("\t}\n\n"))
+	g.writer.Write([]byte("\tid, err := result.LastInsertId()\n"))
+	g.writer.Write([]byte("\tif err != nil {\n"))
+	g.writer.Write([]byte("\t\treturn err\n"))
+	g.writer.Write([]byte("\t}\n"))
+	g.writer.Write([]byte(fmt.Sprintf("\tm.%s = int(id)\n\n", field)))
+	g.writer.Write([]byte("\treturn nil\n"))
+	g.writer.Write([]byte("}\n\n"))
+
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreUpdateMethod(model *ast.Model) error {
+	keys, err := g.keyFields(model)
+	if err != nil {
+		return err
+	}
+
+	query := ""
+
+	for _, item := range model.Items {
+		if !g.isColumn(item) || slices.Contains(keys, item) {
+			continue
+		}
+
+		if query != "" {
+			query += ",\n"
+		}
+
+		column := decorator.ColumnName(g.ast, item)
+		query += "\t\t" + g.quote(column) + "=:" + column
+	}
+
+	// a model made of its key has nothing to update
+	if query == "" {
+		return nil
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET\n%s\n\tWHERE %s", g.quote(decorator.TableName(g.ast, model)), query, g.namedKeyCondition(keys))
+
+	g.writer.Write([]byte(fmt.Sprintf("func (s *%[1]sStore) Update(%[2]s) error {\n", model.Name.Identifier, g.params("m *"+model.Name.Identifier))))
+	g.writer.Write([]byte(fmt.Sprintf("\t_, err := %s\n\n", g.call("NamedExec", goString(query), "m"))))
+	g.writer.Write([]byte("\tif err != nil {\n"))
+	g.writer.Write([]byte("\t\treturn err\n"))
+	g.writer.Write([]byte("\t}\n\n"))
+	g.writer.Write([]byte("\treturn nil\n"))
+	g.writer.Write([]byte("}\n\n"))
+
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreDeleteMethod(model *ast.Model) error {
+	keys, err := g.keyFields(model)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", g.quote(decorator.TableName(g.ast, model)), g.namedKeyCondition(keys))
+
+	code := fmt.Sprintf(`func (s *%[1]sStore) Delete(%[3]s) error {
+	query := %[2]s
+
+	_, err := %[4]s
+  if err != nil {
+    return err
+  }
+
+  return nil
+}
+
+`, model.Name.Identifier, goString(query), g.params("m *"+model.Name.Identifier), g.call("NamedExec", "query", "m"))
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreGetAllMethod(model *ast.Model) error {
+	code := fmt.Sprintf(`func (s *%[1]sStore) GetAll(%[3]s) ([]*%[1]s, error) {
+	var result []*%[1]s
+	query := %[2]s
+
+	err := %[4]s
+  if err != nil {
+    return result, err
+  }
+
+	return result, nil
+}
+
+`, model.Name.Identifier, goString("SELECT * FROM "+g.quote(decorator.TableName(g.ast, model))), g.params(), g.call("Select", "&result", "query"))
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+func (g *SqlxGenerator) generateStoreGetByIdMethod(model *ast.Model) error {
+	keys, err := g.keyFields(model)
+	if err != nil {
+		return err
+	}
+
+	params := []string{}
+	args := []string{"&result", "query"}
+	conditions := []string{}
+	for i, key := range keys {
+		goType, err := g.goType(key)
+		if err != nil {
+			return err
+		}
+
+		param := paramName(key.Identifier.Identifier)
+		params = append(params, param+" "+goType)
+		args = append(args, param)
+		conditions = append(conditions, fmt.Sprintf("%s=%s", g.quote(decorator.ColumnName(g.ast, key)), g.placeholder(i+1)))
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", g.quote(decorator.TableName(g.ast, model)), strings.Join(conditions, " AND "))
+
+	code := fmt.Sprintf(`func (s *%[1]sStore) GetById(%[3]s) (*%[1]s, error) {
+	var result %[1]s
+	query := %[2]s
+
+	err := %[4]s
+  if err != nil {
+    return nil, err
+  }
+
+	return  &result, nil
+}
+
+`, model.Name.Identifier, goString(query), g.params(params...), g.call("Get", args...))
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+// params returns the parameters of a store method, ctx comes first unless
+// the context option is off.
+func (g *SqlxGenerator) params(params ...string) string {
+	if g.context {
+		params = append([]string{"ctx context.Context"}, params...)
+	}
+
+	return strings.Join(params, ", ")
+}
+
+// call returns the call of a DBTX method by a store method, the Context
+// variant of it unless the context option is off.
+func (g *SqlxGenerator) call(method string, args ...string) string {
+	if g.context {
+		method += "Context"
+		args = append([]string{"ctx"}, args...)
+	}
+
+	return fmt.Sprintf("s.conn.%s(%s)", method, strings.Join(args, ", "))
+}
+
+// quote quotes an identifier for the provider of the db block.
+func (g *SqlxGenerator) quote(name string) string {
+	if g.provider == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+// goString renders a query as a Go string literal, a raw string unless the
+// query holds a backtick.
+func goString(query string) string {
+	if strings.Contains(query, "`") {
+		return strconv.Quote(query)
+	}
+
+	return "`" + query + "`"
+}
+
+// generateStoreColumns generates the columns of the model to build the
+// predicates and orderings of its finders, e.g. UserColumns.Name.Eq("Ann").
+func (g *SqlxGenerator) generateStoreColumns(model *ast.Model) error {
+	var fields, columns strings.Builder
+	for _, item := range model.Items {
+		if !g.isColumn(item) {
+			continue
+		}
+
+		valueType, err := g.valueType(item)
+		if err != nil {
+			return err
+		}
+
+		field := utils.Capitalize(item.Identifier.Identifier)
+		fields.WriteString(fmt.Sprintf("\t%s Column[%s]\n", field, valueType))
+		columns.WriteString(fmt.Sprintf("\t%s: Column[%s]{name: %s},\n", field, valueType, strconv.Quote(g.quote(decorator.ColumnName(g.ast, item)))))
+	}
+
+	code := fmt.Sprintf(`// %[1]sColumns are the columns of %[1]s in predicates and orderings.
+var %[1]sColumns = struct {
+%[2]s}{
+%[3]s}
+
+`, model.Name.Identifier, fields.String(), columns.String())
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+// generateStoreFinderMethods generates FindMany, FindFirst, Count, Exists and
+// a GetBy method for every @unique field of the model.
+func (g *SqlxGenerator) generateStoreFinderMethods(model *ast.Model) error {
+	table := g.quote(decorator.TableName(g.ast, model))
+
+	code := fmt.Sprintf(`// FindMany returns the rows matching where sorted by orderBy, a limit of 0
+// returns every row.
+func (s *%[1]sStore) FindMany(%[2]s) ([]*%[1]s, error) {
+	var result []*%[1]s
+	query, args := buildQuery(%[3]s, where, orderBy, limit, offset)
+
+	err := %[4]s
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// FindFirst returns the first row matching where sorted by orderBy.
+func (s *%[1]sStore) FindFirst(%[5]s) (*%[1]s, error) {
+	var result %[1]s
+	query, args := buildQuery(%[3]s, where, orderBy, 1, 0)
+
+	err := %[6]s
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *%[1]sStore) Count(%[7]s) (int, error) {
+	var result int
+	query, args := buildQuery(%[8]s, where, nil, 0, 0)
+
+	err := %[6]s
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (s *%[1]sStore) Exists(%[7]s) (bool, error) {
+	var result bool
+	query, args := buildQuery(%[9]s, where, nil, 0, 0)
+
+	err := %[10]s
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+`,
+		model.Name.Identifier,
+		g.params("where Where", "orderBy OrderBy", "limit int", "offset int"),
+		goString("SELECT * FROM "+table),
+		g.call("Select", "&result", "query", "args..."),
+		g.params("where Where", "orderBy OrderBy"),
+		g.call("Get", "&result", "query", "args..."),
+		g.params("where Where"),
+		goString("SELECT COUNT(*) FROM "+table),
+		goString("SELECT 1 FROM "+table),
+		g.call("Get", "&result", `"SELECT EXISTS ("+query+")"`, "args..."),
+	)
+
+	g.writer.Write([]byte(code))
+
+	keys, _ := g.keyFields(model)
+
+	for _, item := range model.Items {
+		if decorator.ForField(item).Unique == nil || slices.Contains(keys, item) {
+			continue
+		}
+
+		valueType, err := g.valueType(item)
+		if err != nil {
+			return err
+		}
+
+		param := paramName(item.Identifier.Identifier)
+		query := fmt.Sprintf("SELECT * FROM %s WHERE %s=%s", table, g.quote(decorator.ColumnName(g.ast, item)), g.placeholder(1))
+
+		code := fmt.Sprintf(`func (s *%[1]sStore) GetBy%[2]s(%[3]s) (*%[1]s, error) {
+	var result %[1]s
+	query := %[4]s
+
+	err := %[5]s
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+`, model.Name.Identifier, utils.Capitalize(item.Identifier.Identifier), g.params(param+" "+valueType), goString(query), g.call("Get", "&result", "query", param))
+
+		g.writer.Write([]byte(code))
+	}
+
+	return nil
+}
+
+// generateStorePageMethod generates Page, which returns the rows of a model
+// sorted by its @@cursor fields or else by its key. The key is sorted by
+// last if the cursor does not list it, rows with equal cursor values would
+// not be paged otherwise.
+func (g *SqlxGenerator) generateStorePageMethod(model *ast.Model) error {
+	keys, err := g.keyFields(model)
+	if err != nil {
+		return err
+	}
+
+	fields := []*ast.Declaration{}
+	if cursor := decorator.ForModel(model).Cursor; cursor != nil {
+		for _, name := range cursor.Fields {
+			for _, item := range model.Items {
+				if item.Identifier.Identifier == name {
+					fields = append(fields, item)
+				}
+			}
+		}
+	}
+	for _, key := range keys {
+		if !slices.Contains(fields, key) {
+			fields = append(fields, key)
+		}
+	}
+
+	var key strings.Builder
+	targets := []string{}
+	values := []string{}
+	columns := []string{}
+	orderings := []string{}
+	last := []string{}
+	names := []string{}
+	for _, item := range fields {
+		valueType, err := g.valueType(item)
+		if err != nil {
+			return err
+		}
+
+		field := utils.Capitalize(item.Identifier.Identifier)
+		key.WriteString(fmt.Sprintf("\t\t\t%s %s\n", field, valueType))
+		targets = append(targets, "&key."+field)
+		values = append(values, "key."+field)
+		columns = append(columns, fmt.Sprintf("%sColumns.%s.name", model.Name.Identifier, field))
+		orderings = append(orderings, fmt.Sprintf("%sColumns.%s.Asc()", model.Name.Identifier, field))
+		last = append(last, "last."+field)
+		names = append(names, item.Identifier.Identifier)
+	}
+
+	code := fmt.Sprintf(`// Page returns up to limit rows after the cursor sorted by %[2]s, and the
+// cursor of the next page, which is empty after the last page.
+func (s *%[1]sStore) Page(%[3]s) ([]*%[1]s, Cursor, error) {
+	var result []*%[1]s
+
+	where := Where{}
+	if after != "" {
+		var key struct {
+%[4]s		}
+
+		err := after.decode(%[11]s)
+		if err != nil {
+			return nil, "", err
+		}
+
+		where = append(where, keyset([]string{%[6]s}, %[5]s))
+	}
+
+	query, args := buildQuery(%[7]s, where, OrderBy{%[8]s}, limit, 0)
+
+	err := %[9]s
+	if err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 || len(result) < limit {
+		return result, "", nil
+	}
+
+	last := result[len(result)-1]
+	next, err := newCursor(%[10]s)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, next, nil
+}
+
+`,
+		model.Name.Identifier,
+		strings.Join(names, ", "),
+		g.params("after Cursor", "limit int"),
+		key.String(),
+		strings.Join(values, ", "),
+		strings.Join(columns, ", "),
+		goString("SELECT * FROM "+g.quote(decorator.TableName(g.ast, model))),
+		strings.Join(orderings, ", "),
+		g.call("Select", "&result", "query", "args..."),
+		strings.Join(last, ", "),
+		strings.Join(targets, ", "),
+	)
+
+	g.writer.Write([]byte(code))
+	return nil
+}
+
+// keyFields returns the fields of the primary key of the model, its @id
+// field or the fields listed by @@id.
+func (g *SqlxGenerator) keyFields(model *ast.Model) ([]*ast.Declaration, error) {
+	if id, ok := idField(model); ok {
+		return []*ast.Declaration{id}, nil
+	}
+
+	composite := decorator.ForModel(model).Id
+	if composite == nil {
+		return nil, fmt.Errorf("model %s has no @id field", model.Name.Identifier)
+	}
+
+	keys := []*ast.Declaration{}
+	for _, name := range composite.Fields {
+		for _, item := range model.Items {
+			if item.Identifier.Identifier == name {
+				keys = append(keys, item)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// namedKeyCondition matches the primary key with the named parameters of
+// its fields.
+func (g *SqlxGenerator) namedKeyCondition(keys []*ast.Declaration) string {
+	conditions := make([]string, len(keys))
+	for i, key := range keys {
+		column := decorator.ColumnName(g.ast, key)
+		conditions[i] = g.quote(column) + "=:" + column
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+// hasDefault reports whether the field defaults to the function.
+func (g *SqlxGenerator) hasDefault(item *ast.Declaration, function string) bool {
+	def := decorator.ForField(item).Default
+	return def != nil && def.Function == function
+}
+
+// isDefaulted reports whether Insert leaves the column of the field to its
+// default while the field has the zero value. The values of uuid() and
+// ulid() are generated by Insert instead.
+func (g *SqlxGenerator) isDefaulted(item *ast.Declaration) bool {
+	def := decorator.ForField(item).Default
+	if def == nil {
+		return false
+	}
+
+	_, generated := sqlxGeneratedValues[def.Function]
+	return !generated || !g.generatesValue(item, def.Function)
+}
+
+// generatesValue reports whether Insert generates the value of the field
+// with the function, NULL is kept for nullable fields.
+func (g *SqlxGenerator) generatesValue(item *ast.Declaration, function string) bool {
+	return g.hasDefault(item, function) && decorator.ForField(item).Nullable == nil
+}
+
+// usesGeneratedValue reports whether Insert generates the value of a field
+// of the model with the function.
+func (g *SqlxGenerator) usesGeneratedValue(model *ast.Model, function string) bool {
+	for _, item := range model.Items {
+		if g.generatesValue(item, function) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isColumn reports whether the field is a column, relations are not.
+func (g *SqlxGenerator) isColumn(item *ast.Declaration) bool {
+	return item.DeclarationType.Type != ast.VariableTypeObject || g.isTypeEnum(item.DeclarationType)
+}
+
+// placeholder returns the n-th bind parameter of a query, counted from 1.
+func (g *SqlxGenerator) placeholder(n int) string {
+	if g.provider == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+// paramName returns the name of a Go parameter for a field.
+func paramName(name string) string {
+	name = utils.Uncapitalize(name)
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+
+	return name
+}
+
+// generateStoreManyToManyMethods generates the methods linking, unlinking and
+// loading the models of a many-to-many list, e.g. AddTag, RemoveTag and
+// GetTags for a tags list.
+func (g *SqlxGenerator) generateStoreManyToManyMethods(table *analyzer.JoinTable, end *analyzer.RelationEnd) error {
+	other := table.Other(end)
+	field := utils.Capitalize(end.Field.Identifier.Identifier)
+	single := utils.Singular(field)
+	param := utils.Uncapitalize(single)
+
+	id, ok := idField(end.Model)
+	if !ok {
+		return fmt.Errorf("model %s has no @id field", end.Model.Name.Identifier)
+	}
+	otherId, ok := idField(other.Model)
+	if !ok {
+		return fmt.Errorf("model %s has no @id field", other.Model.Name.Identifier)
+	}
+
+	idName := utils.Capitalize(id.Identifier.Identifier)
+	otherIdName := utils.Capitalize(otherId.Identifier.Identifier)
+	joinTable := g.quote(table.Name)
+	column := g.quote(end.Column)
+	otherColumn := g.quote(other.Column)
+	otherTable := g.quote(decorator.TableName(g.ast, other.Model))
+
+	code := fmt.Sprintf(`func (s *%[1]sStore) Add%[3]s(%[11]s) error {
+	query := %[5]s
+
+	_, err := %[12]s
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *%[1]sStore) Remove%[3]s(%[11]s) error {
+	query := %[6]s
+
+	_, err := %[12]s
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *%[1]sStore) Get%[10]s(%[13]s) ([]*%[2]s, error) {
+	var result []*%[2]s
+	query := %[7]s
+
+	err := %[14]s
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+`,
+		end.Model.Name.Identifier,
+		other.Model.Name.Identifier,
+		single,
+		param,
+		goString(fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (%s, %s)", joinTable, column, otherColumn, g.placeholder(1), g.placeholder(2))),
+		goString(fmt.Sprintf("DELETE FROM %s WHERE %s=%s AND %s=%s", joinTable, column, g.placeholder(1), otherColumn, g.placeholder(2))),
+		goString(fmt.Sprintf("SELECT %[1]s.* FROM %[1]s JOIN %[2]s ON %[2]s.%[3]s=%[1]s.%[4]s WHERE %[2]s.%[5]s=%[6]s", otherTable, joinTable, otherColumn, g.quote(decorator.ColumnName(g.ast, otherId)), column, g.placeholder(1))),
+		idName,
+		otherIdName,
+		field,
+		g.params("m *"+end.Model.Name.Identifier, param+" *"+other.Model.Name.Identifier),
+		g.call("Exec", "query", "m."+idName, param+"."+otherIdName),
+		g.params("m *"+end.Model.Name.Identifier),
+		g.call("Select", "&result", "query", "m."+idName),
+	)
 
 	g.writer.Write([]byte(code))
 	return nil
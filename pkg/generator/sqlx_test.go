@@ -1,7 +1,9 @@
 package generator_test
 
 import (
-	"bytes"
+	"os"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/gophoria/gophoria/pkg/generator"
@@ -9,6 +11,36 @@ import (
 	"github.com/gophoria/gophoria/pkg/parser"
 )
 
+func generateSqlx(t *testing.T, input string) string {
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	generator := generator.NewSqlxGenerator()
+
+	err = generator.GenerateAll(ast, cfg)
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	return workingDir
+}
+
+func readGenerated(t *testing.T, workingDir string, name string) string {
+	data, err := os.ReadFile(path.Join(workingDir, "db", name))
+	if err != nil {
+		t.Fatalf("unable to read %s: %s", name, err.Error())
+	}
+
+	return string(data)
+}
+
 func TestSqlx(t *testing.T) {
 	input := `
 db {
@@ -38,31 +70,586 @@ model Post {
   authorId  int
 }`
 
-	expected := `type Role string
+	expected := map[string]string{
+		"Role.go": `package db
+
+type Role string
 
 const (
   RoleAdmin Role = "admin"
-  RoleUser = "user"
+  RoleUser Role = "user"
 )
 
-type User struct {
-  Id string
-  Name string
-  Surname string
-  Role Role
-  Posts []*Post
+`,
+		"User.go": "type User struct {\n" +
+			"  Id string `db:\"id\"`\n" +
+			"  Name string `db:\"name\"`\n" +
+			"  Surname string `db:\"surname\"`\n" +
+			"  Role Role `db:\"role\"`\n" +
+			"  Posts []*Post `db:\"posts\"`\n" +
+			"}\n",
+		"Post.go": "type Post struct {\n" +
+			"  Id int `db:\"id\"`\n" +
+			"  Title string `db:\"title\"`\n" +
+			"  Content sql.NullString `db:\"content\"`\n" +
+			"  Public bool `db:\"public\"`\n" +
+			"  Author *User `db:\"author\"`\n" +
+			"  AuthorId int `db:\"authorId\"`\n" +
+			"}\n",
+	}
+
+	workingDir := generateSqlx(t, input)
+
+	for name, exp := range expected {
+		output := readGenerated(t, workingDir, name)
+		if !strings.Contains(output, exp) {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+		}
+	}
+}
+
+func TestSqlxNullable(t *testing.T) {
+	fields := `
+enum Role {
+  admin = "admin"
+}
+
+model User {
+  id      int      @id
+  name    string   @nullable
+  age     int      @nullable
+  score   real     @nullable
+  active  bool     @nullable
+  birth   DateTime @nullable
+  role    Role     @nullable
+  manager User     @nullable
+}`
+
+	tests := []struct {
+		option   string
+		usesSql  bool
+		expected string
+	}{
+		{
+			option:  "",
+			usesSql: true,
+			expected: "type User struct {\n" +
+				"  Id int `db:\"id\"`\n" +
+				"  Name sql.NullString `db:\"name\"`\n" +
+				"  Age sql.NullInt64 `db:\"age\"`\n" +
+				"  Score sql.NullFloat64 `db:\"score\"`\n" +
+				"  Active sql.NullBool `db:\"active\"`\n" +
+				"  Birth NullDateTime `db:\"birth\"`\n" +
+				"  Role NullRole `db:\"role\"`\n" +
+				"  Manager *User `db:\"manager\"`\n" +
+				"}\n",
+		},
+		{
+			option: `db {
+  nullable = "generic"
+}`,
+			usesSql: true,
+			expected: "type User struct {\n" +
+				"  Id int `db:\"id\"`\n" +
+				"  Name sql.Null[string] `db:\"name\"`\n" +
+				"  Age sql.Null[int] `db:\"age\"`\n" +
+				"  Score sql.Null[float64] `db:\"score\"`\n" +
+				"  Active sql.Null[bool] `db:\"active\"`\n" +
+				"  Birth sql.Null[DateTime] `db:\"birth\"`\n" +
+				"  Role sql.Null[Role] `db:\"role\"`\n" +
+				"  Manager *User `db:\"manager\"`\n" +
+				"}\n",
+		},
+		{
+			option: `db {
+  nullable = "pointer"
+}`,
+			expected: "type User struct {\n" +
+				"  Id int `db:\"id\"`\n" +
+				"  Name *string `db:\"name\"`\n" +
+				"  Age *int `db:\"age\"`\n" +
+				"  Score *float64 `db:\"score\"`\n" +
+				"  Active *bool `db:\"active\"`\n" +
+				"  Birth *DateTime `db:\"birth\"`\n" +
+				"  Role *Role `db:\"role\"`\n" +
+				"  Manager *User `db:\"manager\"`\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		workingDir := generateSqlx(t, test.option+fields)
+
+		output := readGenerated(t, workingDir, "User.go")
+		if !strings.Contains(output, test.expected) {
+			t.Fatalf("Generator output for nullable option %q is not correct:\n%s", test.option, output)
+		}
+
+		if strings.Contains(output, "\"database/sql\"") != test.usesSql {
+			t.Fatalf("unexpected database/sql import for nullable option %q:\n%s", test.option, output)
+		}
+
+		// sql.Null[T] needs Go 1.22, enums get a Null type of their own
+		output = readGenerated(t, workingDir, "Role.go")
+		if strings.Contains(output, "type NullRole struct {\n\tRole Role\n\tValid bool\n}\n") != (test.option == "") {
+			t.Fatalf("unexpected NullRole for nullable option %q:\n%s", test.option, output)
+		}
+	}
 }
 
-type Post struct {
-  Id int
-  Title string
-  Content string
-  Public bool
-  Author *User
-  AuthorId int
+func TestSqlxDocComments(t *testing.T) {
+	input := `
+/// Role of a user.
+enum Role {
+  /// Administrator.
+  admin = "admin"
+  user = "user"
 }
 
-`
+// not a doc comment
+/// User of the system.
+model User {
+  /// Unique identifier.
+  id      string  @id
+  name    string
+}`
+
+	expected := map[string]string{
+		"Role.go": `package db
+
+// Role of a user.
+type Role string
+
+const (
+  // Administrator.
+  RoleAdmin Role = "admin"
+  RoleUser Role = "user"
+)
+
+`,
+		"User.go": "// User of the system.\n" +
+			"type User struct {\n" +
+			"  // Unique identifier.\n" +
+			"  Id string `db:\"id\"`\n" +
+			"  Name string `db:\"name\"`\n" +
+			"}\n",
+	}
+
+	workingDir := generateSqlx(t, input)
+
+	for name, exp := range expected {
+		output := readGenerated(t, workingDir, name)
+		if !strings.Contains(output, exp) {
+			t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+		}
+	}
+}
+
+func TestSqlxManyToMany(t *testing.T) {
+	input := `
+model Post {
+  id      int     @id
+  tags    Tag[]   @relation(name: "PostTags")
+}
+
+model Tag {
+  id      string  @id
+  posts   Post[]  @relation(name: "PostTags")
+}`
+
+	expected := map[string][]string{
+		"Post.go": {
+			"func (s *PostStore) AddTag(ctx context.Context, m *Post, tag *Tag) error {\n" +
+				"\tquery := `INSERT INTO \"PostTags\" (\"postId\", \"tagId\") VALUES (?, ?)`\n\n" +
+				"\t_, err := s.conn.ExecContext(ctx, query, m.Id, tag.Id)\n",
+			"func (s *PostStore) RemoveTag(ctx context.Context, m *Post, tag *Tag) error {\n" +
+				"\tquery := `DELETE FROM \"PostTags\" WHERE \"postId\"=? AND \"tagId\"=?`\n",
+			"func (s *PostStore) GetTags(ctx context.Context, m *Post) ([]*Tag, error) {\n" +
+				"\tvar result []*Tag\n" +
+				"\tquery := `SELECT \"Tag\".* FROM \"Tag\" JOIN \"PostTags\" ON \"PostTags\".\"tagId\"=\"Tag\".\"id\" WHERE \"PostTags\".\"postId\"=?`\n",
+		},
+		"Tag.go": {
+			"func (s *TagStore) AddPost(ctx context.Context, m *Tag, post *Post) error {\n",
+			"func (s *TagStore) RemovePost(ctx context.Context, m *Tag, post *Post) error {\n",
+			"func (s *TagStore) GetPosts(ctx context.Context, m *Tag) ([]*Post, error) {\n",
+		},
+	}
+
+	workingDir := generateSqlx(t, input)
+
+	for name, snippets := range expected {
+		output := readGenerated(t, workingDir, name)
+		for _, exp := range snippets {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+			}
+		}
+	}
+}
+
+func TestSqlxMapped(t *testing.T) {
+	input := `
+model User {
+  key     string  @id @map("user_key")
+  name    string  @map("user_name")
+
+  @@map("users")
+}`
+
+	expected := []string{
+		"  Key string `db:\"user_key\"`\n" +
+			"  Name string `db:\"user_name\"`\n",
+		"`INSERT INTO \"users\" (\n\t\t\"user_key\",\n\t\t\"user_name\"\n\t) VALUES (\n\t\t:user_key,\n\t\t:user_name\n\t)`",
+		"`UPDATE \"users\" SET\n\t\t\"user_name\"=:user_name\n\tWHERE \"user_key\"=:user_key`",
+		"query := `DELETE FROM \"users\" WHERE \"user_key\"=:user_key`",
+		"query := `SELECT * FROM \"users\" WHERE \"user_key\"=?`",
+	}
+
+	output := readGenerated(t, generateSqlx(t, input), "User.go")
+	for _, exp := range expected {
+		if !strings.Contains(output, exp) {
+			t.Fatalf("Generator output for User.go is not correct:\n%s", output)
+		}
+	}
+}
+
+func TestSqlxNaming(t *testing.T) {
+	input := `
+db {
+  provider = "mysql"
+  naming = "snake_plural"
+}
+
+model BlogPost {
+  id        int     @id
+  createdAt DateTime
+  tags      Tag[]
+}
+
+model Tag {
+  id        int     @id
+  posts     BlogPost[]
+}`
+
+	expected := []string{
+		"  CreatedAt DateTime `db:\"created_at\"`\n",
+		"s.conn.NamedExecContext(ctx, \"INSERT INTO `blog_posts` (\\n\\t\\t`id`,\\n\\t\\t`created_at`\\n\\t) VALUES (\\n\\t\\t:id,\\n\\t\\t:created_at\\n\\t)\", m)",
+		"query := \"DELETE FROM `blog_posts` WHERE `id`=:id\"",
+		"query := \"INSERT INTO `_blog_post_to_tag` (`blog_post_id`, `tag_id`) VALUES (?, ?)\"",
+	}
+
+	output := readGenerated(t, generateSqlx(t, input), "BlogPost.go")
+	for _, exp := range expected {
+		if !strings.Contains(output, exp) {
+			t.Fatalf("Generator output for BlogPost.go is not correct:\n%s", output)
+		}
+	}
+}
+
+func TestSqlxQueries(t *testing.T) {
+	input := `
+model User {
+  id      string  @id
+}
+
+model Post {
+  id      string  @id
+}`
+
+	workingDir := generateSqlx(t, input)
+
+	expected := map[string][]string{
+		"Queries.go": {
+			"type DBTX interface {\n",
+			"type Queries struct {\n" +
+				"\tconn DBTX\n" +
+				"\tUser *UserStore\n" +
+				"\tPost *PostStore\n" +
+				"}\n",
+			"\t\tUser: NewUserStore(conn),\n" +
+				"\t\tPost: NewPostStore(conn),\n",
+			"func (q *Queries) RunInTx(ctx context.Context, fn func(q *Queries) error) error {\n" +
+				"\tswitch conn := q.conn.(type) {\n" +
+				"\tcase *sqlx.DB:\n",
+			"\tcase *sqlx.Tx:\n" +
+				"\t\treturn fn(q)\n" +
+				"\tdefault:\n" +
+				"\t\treturn fmt.Errorf(\"unable to run a transaction on %T\", conn)\n",
+		},
+		"User.go": {
+			"type UserStore struct {\n  conn DBTX\n}\n",
+			"func NewUserStore(conn DBTX) *UserStore {\n",
+			"func (s *UserStore) WithTx(tx *sqlx.Tx) *UserStore {\n",
+		},
+	}
+
+	for name, snippets := range expected {
+		output := readGenerated(t, workingDir, name)
+		for _, exp := range snippets {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+			}
+		}
+	}
+}
+
+func TestSqlxContext(t *testing.T) {
+	model := `
+model User {
+  id      string  @id
+}`
+
+	tests := []struct {
+		option   string
+		expected []string
+	}{
+		{
+			option: "",
+			expected: []string{
+				"\t\"context\"\n",
+				"func (s *UserStore) Insert(ctx context.Context, m *User) error {\n",
+				"\t_, err := s.conn.NamedExecContext(ctx, `INSERT INTO",
+				"func (s *UserStore) Delete(ctx context.Context, m *User) error {\n",
+				"\t_, err := s.conn.NamedExecContext(ctx, query, m)\n",
+				"func (s *UserStore) GetAll(ctx context.Context) ([]*User, error) {\n",
+				"\terr := s.conn.SelectContext(ctx, &result, query)\n",
+				"func (s *UserStore) GetById(ctx context.Context, id string) (*User, error) {\n",
+				"\terr := s.conn.GetContext(ctx, &result, query, id)\n",
+			},
+		},
+		{
+			option: `db {
+  context = false
+}`,
+			expected: []string{
+				"func (s *UserStore) Insert(m *User) error {\n",
+				"\t_, err := s.conn.NamedExec(`INSERT INTO",
+				"func (s *UserStore) Delete(m *User) error {\n",
+				"\t_, err := s.conn.NamedExec(query, m)\n",
+				"func (s *UserStore) GetAll() ([]*User, error) {\n",
+				"\terr := s.conn.Select(&result, query)\n",
+				"func (s *UserStore) GetById(id string) (*User, error) {\n",
+				"\terr := s.conn.Get(&result, query, id)\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		output := readGenerated(t, generateSqlx(t, test.option+model), "User.go")
+		for _, exp := range test.expected {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for context option %q is not correct:\n%s", test.option, output)
+			}
+		}
+
+		if strings.Contains(output, "\"context\"") != (test.option == "") {
+			t.Fatalf("unexpected context import for context option %q:\n%s", test.option, output)
+		}
+	}
+}
+
+func TestSqlxKeys(t *testing.T) {
+	models := `
+model User {
+  id      string  @id @default(uuid())
+  name    string
+}
+
+model Post {
+  id      int     @id @default(autoincrement())
+  title   string
+}
+
+model Event {
+  key     string  @id @default(ulid())
+}
+
+model Membership {
+  userId  string
+  groupId int
+  role    string
+
+  @@id([userId, groupId])
+}`
+
+	tests := []struct {
+		provider string
+		expected map[string][]string
+	}{
+		{
+			provider: "sqlite3",
+			expected: map[string][]string{
+				"User.go": {
+					"\t\"github.com/google/uuid\"\n",
+					"\tif m.Id == \"\" {\n\t\tm.Id = uuid.NewString()\n\t}\n",
+					"func (s *UserStore) GetById(ctx context.Context, id string) (*User, error) {\n",
+				},
+				"Post.go": {
+					"\tresult, err := s.conn.NamedExecContext(ctx, `INSERT INTO \"Post\" (\n\t\t\"title\"\n\t) VALUES (\n\t\t:title\n\t)`, m)\n",
+					"\tid, err := result.LastInsertId()\n",
+					"\tm.Id = int(id)\n",
+					"func (s *PostStore) GetById(ctx context.Context, id int) (*Post, error) {\n",
+				},
+				"Event.go": {
+					"\t\"github.com/oklog/ulid/v2\"\n",
+					"\tif m.Key == \"\" {\n\t\tm.Key = ulid.Make().String()\n\t}\n",
+					"query := `SELECT * FROM \"Event\" WHERE \"key\"=?`",
+				},
+				"Membership.go": {
+					"`UPDATE \"Membership\" SET\n\t\t\"role\"=:role\n\tWHERE \"userId\"=:userId AND \"groupId\"=:groupId`",
+					"query := `DELETE FROM \"Membership\" WHERE \"userId\"=:userId AND \"groupId\"=:groupId`",
+					"func (s *MembershipStore) GetById(ctx context.Context, userId string, groupId int) (*Membership, error) {\n",
+					"query := `SELECT * FROM \"Membership\" WHERE \"userId\"=? AND \"groupId\"=?`",
+					"\terr := s.conn.GetContext(ctx, &result, query, userId, groupId)\n",
+				},
+			},
+		},
+		{
+			provider: "postgres",
+			expected: map[string][]string{
+				"Post.go": {
+					"\tquery, args, err := sqlx.Named(`INSERT INTO \"Post\" (\n\t\t\"title\"\n\t) VALUES (\n\t\t:title\n\t) RETURNING \"id\"`, m)\n",
+					"\treturn s.conn.GetContext(ctx, &m.Id, sqlx.Rebind(sqlx.DOLLAR, query), args...)\n",
+				},
+				"Membership.go": {
+					"query := `SELECT * FROM \"Membership\" WHERE \"userId\"=$1 AND \"groupId\"=$2`",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		workingDir := generateSqlx(t, "db {\n  provider = \""+test.provider+"\"\n}\n"+models)
+
+		for name, snippets := range test.expected {
+			output := readGenerated(t, workingDir, name)
+			for _, exp := range snippets {
+				if !strings.Contains(output, exp) {
+					t.Fatalf("Generator output of %s for %s is not correct:\n%s", name, test.provider, output)
+				}
+			}
+
+			if name != "User.go" && strings.Contains(output, "uuid") {
+				t.Fatalf("unexpected uuid in %s for %s:\n%s", name, test.provider, output)
+			}
+		}
+	}
+}
+
+func TestSqlxFinders(t *testing.T) {
+	input := `
+db {
+  provider = "postgres"
+}
+
+model User {
+  id      int     @id
+  email   string  @unique
+  age     int     @nullable
+}`
+
+	workingDir := generateSqlx(t, input)
+
+	expected := map[string][]string{
+		"Where.go": {
+			"func (c Column[T]) Eq(value T) Predicate {\n",
+			"func (c Column[T]) In(values ...T) Predicate {\n",
+			"func (c Column[T]) IsNull() Predicate {\n",
+			"\treturn sqlx.Rebind(sqlx.DOLLAR, sb.String()), args\n",
+		},
+		"User.go": {
+			"var UserColumns = struct {\n" +
+				"\tId Column[int]\n" +
+				"\tEmail Column[string]\n" +
+				"\tAge Column[int]\n" +
+				"}{\n" +
+				"\tId: Column[int]{name: \"\\\"id\\\"\"},\n",
+			"func (s *UserStore) FindMany(ctx context.Context, where Where, orderBy OrderBy, limit int, offset int) ([]*User, error) {\n",
+			"\tquery, args := buildQuery(`SELECT * FROM \"User\"`, where, orderBy, limit, offset)\n",
+			"func (s *UserStore) FindFirst(ctx context.Context, where Where, orderBy OrderBy) (*User, error) {\n",
+			"func (s *UserStore) Count(ctx context.Context, where Where) (int, error) {\n",
+			"\tquery, args := buildQuery(`SELECT COUNT(*) FROM \"User\"`, where, nil, 0, 0)\n",
+			"func (s *UserStore) Exists(ctx context.Context, where Where) (bool, error) {\n",
+			"\terr := s.conn.GetContext(ctx, &result, \"SELECT EXISTS (\"+query+\")\", args...)\n",
+			"func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {\n" +
+				"\tvar result User\n" +
+				"\tquery := `SELECT * FROM \"User\" WHERE \"email\"=$1`\n",
+		},
+	}
+
+	for name, snippets := range expected {
+		output := readGenerated(t, workingDir, name)
+		for _, exp := range snippets {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+			}
+		}
+	}
+
+	// the key is found by GetById only
+	output := readGenerated(t, workingDir, "User.go")
+	if strings.Count(output, "func (s *UserStore) GetBy") != 2 {
+		t.Fatalf("expected GetById and GetByEmail only:\n%s", output)
+	}
+}
+
+func TestSqlxPage(t *testing.T) {
+	input := `
+model Post {
+  id        int      @id
+  createdAt DateTime
+
+  @@cursor([createdAt])
+}
+
+model Tag {
+  name      string   @id
+}`
+
+	workingDir := generateSqlx(t, input)
+
+	expected := map[string][]string{
+		"Where.go": {
+			"type Cursor string\n",
+			"func keyset(columns []string, values ...any) Predicate {\n",
+		},
+		"Post.go": {
+			"func (s *PostStore) Page(ctx context.Context, after Cursor, limit int) ([]*Post, Cursor, error) {\n",
+			"\t\tvar key struct {\n\t\t\tCreatedAt DateTime\n\t\t\tId int\n\t\t}\n",
+			"\t\terr := after.decode(&key.CreatedAt, &key.Id)\n",
+			"\t\twhere = append(where, keyset([]string{PostColumns.CreatedAt.name, PostColumns.Id.name}, key.CreatedAt, key.Id))\n",
+			"\tquery, args := buildQuery(`SELECT * FROM \"Post\"`, where, OrderBy{PostColumns.CreatedAt.Asc(), PostColumns.Id.Asc()}, limit, 0)\n",
+			"\tnext, err := newCursor(last.CreatedAt, last.Id)\n",
+		},
+		"Tag.go": {
+			"\t\terr := after.decode(&key.Name)\n",
+			"OrderBy{TagColumns.Name.Asc()}",
+		},
+	}
+
+	for name, snippets := range expected {
+		output := readGenerated(t, workingDir, name)
+		for _, exp := range snippets {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+			}
+		}
+	}
+}
+
+func TestSqlxPageTime(t *testing.T) {
+	input := `
+db {
+  provider = "postgres"
+  lib = "sqlx"
+}
+
+model Post {
+  id        int      @id
+  createdAt DateTime @default(now())
+
+  @@cursor([createdAt])
+}`
 
 	lexer := lexer.NewLexer(input)
 	parser := parser.NewParser(lexer)
@@ -72,15 +659,94 @@ type Post struct {
 		t.Fatalf("parser error: %s", err.Error())
 	}
 
-	generator := generator.NewSqlxGenerator()
+	workingDir := t.TempDir()
+	cfg := &generator.GeneratorConfig{WorkingDir: workingDir}
+	gen := generator.NewSqlxGenerator()
 
-	var buffer bytes.Buffer
-	err = generator.GenerateAll(ast, nil)
+	err = gen.GenerateAll(ast, cfg)
 	if err != nil {
 		t.Fatalf("generator error: %s", err.Error())
 	}
+	err = gen.Generate(ast, cfg, "DateTime")
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	// a cursor at 12:00:00.5 has to keep the half second, the row at
+	// 12:00:00.5 would be returned again otherwise
+	expected := map[string][]string{
+		"Where.go": {
+			"\t\"time\"\n",
+			"\t\tif t, ok := value.(interface{ Time() time.Time }); ok {\n\t\t\tvalues[i] = t.Time()\n",
+			"\t\tif _, ok := value.(json.Marshaler); ok {\n\t\t\tcontinue\n",
+			"\t\t_, unmarshaler := value.(json.Unmarshaler)\n",
+		},
+		"DateTime.go": {
+			"\treturn json.Marshal(time.Time(d).Format(time.RFC3339Nano))\n",
+			"\tdateTime, err := time.Parse(time.RFC3339Nano, source)\n",
+		},
+	}
+
+	for name, snippets := range expected {
+		output := readGenerated(t, workingDir, name)
+		for _, exp := range snippets {
+			if !strings.Contains(output, exp) {
+				t.Fatalf("Generator output for %s is not correct:\n%s", name, output)
+			}
+		}
+	}
+
+	output := readGenerated(t, generateSqlx(t, "model Post {\n  id int @id\n}"), "Where.go")
+	if strings.Contains(output, "\"time\"") {
+		t.Fatalf("sqlite cursors should compare DateTime values as text:\n%s", output)
+	}
+}
+
+func TestSqlxNoKey(t *testing.T) {
+	input := `
+model Log {
+  message string
+}`
+
+	ast, err := parser.NewParser(lexer.NewLexer(input)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	cfg := &generator.GeneratorConfig{WorkingDir: t.TempDir()}
+	err = generator.NewSqlxGenerator().GenerateAll(ast, cfg)
+	if err == nil {
+		t.Fatalf("expected an error for a model without a key")
+	}
+}
+
+func TestSqlxInsertDefaults(t *testing.T) {
+	input := `
+db {
+  provider = "sqlite3"
+}
+
+model User {
+  id        int      @id @default(autoincrement())
+  name      string
+  score     real     @default(-1.5)
+  createdAt DateTime @default(now())
+}`
+
+	output := readGenerated(t, generateSqlx(t, input), "User.go")
+
+	expected := []string{
+		"// Insert adds the row, a column with a default is left to the database while\n// its field has the zero value.\n",
+		"\tcolumns := []string{`\"name\"`}\n\tvalues := []string{\":name\"}\n",
+		"\tif !isZero(m.Score) {\n\t\tcolumns = append(columns, `\"score\"`)\n\t\tvalues = append(values, \":score\")\n\t}\n",
+		"\tif !isZero(m.CreatedAt) {\n",
+		"\tquery := insertQuery(`\"User\"`, columns, values)\n",
+		"\tresult, err := s.conn.NamedExecContext(ctx, query, m)\n",
+	}
 
-	if buffer.String() != expected {
-		t.Fatalf("Generator output is not correct")
+	for _, exp := range expected {
+		if !strings.Contains(output, exp) {
+			t.Fatalf("Generator output for User.go is not correct:\n%s", output)
+		}
 	}
 }
@@ -0,0 +1,153 @@
+package introspect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+var introspectors = map[string]Introspector{}
+
+// Introspector reads the schema of an existing database into enums and
+// models, the config blocks of the returned ast are left empty. Tables and
+// columns the naming would not derive from their model or field get @@map
+// and @map.
+type Introspector interface {
+	Introspect(db *sql.DB, naming *decorator.Naming) (*ast.Ast, error)
+}
+
+func GetIntrospector(name string) (Introspector, error) {
+	introspector, ok := introspectors[name]
+	if !ok {
+		return nil, fmt.Errorf("introspection of %s is not supported", name)
+	}
+
+	return introspector, nil
+}
+
+func RegisterIntrospector(name string, introspector Introspector) {
+	_, ok := introspectors[name]
+	if ok {
+		panic(fmt.Sprintf("introspector %s already exists", name))
+	}
+
+	introspectors[name] = introspector
+}
+
+// Identifier turns the name of a table, column or enum value into a name
+// the lexer reads as a single identifier, which is not a keyword.
+func Identifier(name string) string {
+	var sb strings.Builder
+	for _, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z':
+			sb.WriteRune(ch)
+		case ch >= '0' && ch <= '9', ch == '_':
+			if sb.Len() > 0 {
+				sb.WriteRune(ch)
+			}
+		default:
+			if sb.Len() > 0 {
+				sb.WriteRune('_')
+			}
+		}
+	}
+
+	ident := sb.String()
+	if ident == "" {
+		return "unnamed"
+	}
+
+	if lexer.NewLexer(ident).Next().Type != lexer.TokenTypeIdent {
+		ident += "_"
+	}
+
+	return ident
+}
+
+func newIdentifier(name string) *ast.Identifier {
+	return ast.NewIdentifier(lexer.NewToken(lexer.TokenTypeIdent, name, 0, 0))
+}
+
+func newModel(name string) *ast.Model {
+	return ast.NewModel(lexer.NewToken(lexer.TokenTypeModel, "model", 0, 0), newIdentifier(name))
+}
+
+func newEnum(name string) *ast.Enum {
+	return ast.NewEnum(lexer.NewToken(lexer.TokenTypeEnum, "enum", 0, 0), newIdentifier(name))
+}
+
+func newEnumItem(name string, value string) *ast.AssignItem {
+	token := lexer.NewToken(lexer.TokenTypeAssign, "=", 0, 0)
+	return ast.NewAssignItem(token, newIdentifier(name), newString(value))
+}
+
+// newField declares a field, typeName is a scalar type like int or the name
+// of an enum or model.
+func newField(name string, typeName string, isArray bool) *ast.Declaration {
+	token := lexer.NewLexer(typeName).Next()
+	return ast.NewDeclaration(newIdentifier(name), ast.NewDeclarationType(token, isArray))
+}
+
+func newDecorator(name string, args ...*ast.Argument) *ast.Decorator {
+	dec := ast.NewDecorator(lexer.NewToken(lexer.TokenTypeDecorator, "@", 0, 0), newIdentifier(name))
+	if len(args) > 0 {
+		callable := ast.NewCallable(newIdentifier(name))
+		for _, arg := range args {
+			callable.AddArgument(arg)
+		}
+		dec.SetCallable(callable)
+	}
+
+	return dec
+}
+
+func newModelDecorator(name string, args ...*ast.Argument) *ast.Decorator {
+	dec := newDecorator(name, args...)
+	dec.Token = lexer.NewToken(lexer.TokenTypeModelDecorator, "@@", 0, 0)
+
+	return dec
+}
+
+func newString(value string) *ast.Value {
+	return ast.NewValue(lexer.NewToken(lexer.TokenTypeString, value, 0, 0))
+}
+
+func newBool(value bool) *ast.Value {
+	if value {
+		return ast.NewValue(lexer.NewToken(lexer.TokenTypeTrue, "true", 0, 0))
+	}
+
+	return ast.NewValue(lexer.NewToken(lexer.TokenTypeFalse, "false", 0, 0))
+}
+
+func newIdentValue(value string) *ast.Value {
+	return ast.NewValue(lexer.NewToken(lexer.TokenTypeIdent, value, 0, 0))
+}
+
+func newList(values ...string) *ast.Value {
+	list := ast.NewListValue(lexer.NewToken(lexer.TokenTypeLSquareBrace, "[", 0, 0))
+	for _, value := range values {
+		list.AddItem(newIdentValue(value))
+	}
+
+	return list
+}
+
+// newFunction is an argument calling a function without arguments, like
+// now().
+func newFunction(name string) *ast.Argument {
+	return ast.NewArgument(nil, nil, ast.NewCallable(newIdentifier(name)))
+}
+
+func newArgument(name string, value *ast.Value) *ast.Argument {
+	if name == "" {
+		return ast.NewArgument(nil, value, nil)
+	}
+
+	return ast.NewArgument(newIdentifier(name), value, nil)
+}
@@ -0,0 +1,637 @@
+package introspect
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gophoria/gophoria/internal/utils"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+// sqlite3Check matches a CHECK (column IN (...)) constraint, the way an enum
+// is stored in a TEXT column.
+var sqlite3Check = regexp.MustCompile("(?i)CHECK\\s*\\(\\s*[\"`\\[]?(\\w+)[\"`\\]]?\\s+IN\\s*\\(([^)]*)\\)\\s*\\)")
+
+var sqlite3Actions = map[string]string{
+	"CASCADE":  decorator.ActionCascade,
+	"SET NULL": decorator.ActionSetNull,
+	"RESTRICT": decorator.ActionRestrict,
+}
+
+func init() {
+	RegisterIntrospector("sqlite3", NewSqlite3Introspector())
+}
+
+type sqlite3Table struct {
+	name        string
+	sql         string
+	model       *ast.Model
+	columns     []*sqlite3Column
+	foreignKeys []*sqlite3ForeignKey
+	uniques     [][]string
+	enums       map[string][]string
+}
+
+type sqlite3Column struct {
+	name         string
+	declaredType string
+	notNull      bool
+	defaultValue sql.NullString
+	pk           int
+}
+
+type sqlite3ForeignKey struct {
+	table    string
+	from     []string
+	to       []string
+	onUpdate string
+	onDelete string
+}
+
+type Sqlite3Introspector struct {
+	db     *sql.DB
+	naming *decorator.Naming
+	schema *ast.Ast
+	tables map[string]*sqlite3Table
+}
+
+func NewSqlite3Introspector() *Sqlite3Introspector {
+	i := Sqlite3Introspector{}
+
+	return &i
+}
+
+// Introspect maps every table to a model. Columns get the scalar type of
+// their affinity, so a bool or DateTime written as INTEGER or TEXT comes
+// back as int or string. Join tables of many-to-many relations named the
+// way gophoria names them become list fields of both models.
+func (i *Sqlite3Introspector) Introspect(db *sql.DB, naming *decorator.Naming) (*ast.Ast, error) {
+	i.db = db
+	i.naming = naming
+	i.schema = ast.NewAst()
+	i.tables = map[string]*sqlite3Table{}
+
+	tables, err := i.readTables()
+	if err != nil {
+		return nil, err
+	}
+
+	models := []*sqlite3Table{}
+	joinTables := []*sqlite3Table{}
+	for _, table := range tables {
+		i.tables[table.name] = table
+		if i.isJoinTable(table) {
+			joinTables = append(joinTables, table)
+			continue
+		}
+
+		table.model = newModel(Identifier(table.name))
+		models = append(models, table)
+		i.schema.Models = append(i.schema.Models, table.model)
+	}
+
+	for _, table := range models {
+		i.addFields(table)
+	}
+
+	for _, table := range models {
+		i.addInverseFields(table)
+	}
+
+	for _, table := range joinTables {
+		i.addManyToMany(table)
+	}
+
+	return i.schema, nil
+}
+
+func (i *Sqlite3Introspector) readTables() ([]*sqlite3Table, error) {
+	rows, err := i.db.Query("SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != '_gophoria_migrations' ORDER BY rowid")
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []*sqlite3Table{}
+	for rows.Next() {
+		table := sqlite3Table{enums: map[string][]string{}}
+
+		err = rows.Scan(&table.name, &table.sql)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		tables = append(tables, &table)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		err = i.readColumns(table)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.readForeignKeys(table)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.readUniques(table)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range sqlite3Check.FindAllStringSubmatch(table.sql, -1) {
+			values, ok := enumValues(match[2])
+			if ok {
+				table.enums[match[1]] = values
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+func (i *Sqlite3Introspector) readColumns(table *sqlite3Table) error {
+	rows, err := i.db.Query("SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?)", table.name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		column := sqlite3Column{}
+
+		err = rows.Scan(&column.name, &column.declaredType, &column.notNull, &column.defaultValue, &column.pk)
+		if err != nil {
+			return err
+		}
+
+		table.columns = append(table.columns, &column)
+	}
+
+	return rows.Err()
+}
+
+func (i *Sqlite3Introspector) readForeignKeys(table *sqlite3Table) error {
+	rows, err := i.db.Query("SELECT id, \"table\", \"from\", \"to\", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq", table.name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byId := map[int]*sqlite3ForeignKey{}
+	for rows.Next() {
+		id := 0
+		to := sql.NullString{}
+		fk := sqlite3ForeignKey{}
+		from := ""
+
+		err = rows.Scan(&id, &fk.table, &from, &to, &fk.onUpdate, &fk.onDelete)
+		if err != nil {
+			return err
+		}
+
+		existing, ok := byId[id]
+		if !ok {
+			existing = &fk
+			byId[id] = existing
+			table.foreignKeys = append(table.foreignKeys, existing)
+		}
+
+		existing.from = append(existing.from, from)
+		existing.to = append(existing.to, to.String)
+	}
+
+	return rows.Err()
+}
+
+// readUniques reads the unique indexes of a table, except its primary key.
+func (i *Sqlite3Introspector) readUniques(table *sqlite3Table) error {
+	rows, err := i.db.Query("SELECT name FROM pragma_index_list(?) WHERE \"unique\" = 1 AND origin != 'pk' ORDER BY seq DESC", table.name)
+	if err != nil {
+		return err
+	}
+
+	indexes := []string{}
+	for rows.Next() {
+		name := ""
+
+		err = rows.Scan(&name)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		indexes = append(indexes, name)
+	}
+	rows.Close()
+
+	for _, index := range indexes {
+		columns, err := i.readIndexColumns(index)
+		if err != nil {
+			return err
+		}
+
+		table.uniques = append(table.uniques, columns)
+	}
+
+	return nil
+}
+
+func (i *Sqlite3Introspector) readIndexColumns(index string) ([]string, error) {
+	rows, err := i.db.Query("SELECT name FROM pragma_index_info(?) ORDER BY seqno", index)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		name := ""
+
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
+
+// isJoinTable tells whether a table only links two rows by their ids, with
+// the columns gophoria gives the join table of a many-to-many relation under
+// the naming.
+func (i *Sqlite3Introspector) isJoinTable(table *sqlite3Table) bool {
+	if len(table.columns) != 2 || len(table.foreignKeys) != 2 {
+		return false
+	}
+
+	for _, column := range table.columns {
+		if column.pk == 0 {
+			return false
+		}
+	}
+
+	a, b := table.foreignKeys[0], table.foreignKeys[1]
+	if len(a.from) != 1 || len(b.from) != 1 {
+		return false
+	}
+
+	if a.table == b.table {
+		return true
+	}
+
+	if Identifier(a.table) > Identifier(b.table) {
+		a, b = b, a
+	}
+
+	return a.from[0] == i.naming.Column(utils.Uncapitalize(Identifier(a.table))+"Id") && b.from[0] == i.naming.Column(utils.Uncapitalize(Identifier(b.table))+"Id")
+}
+
+func (i *Sqlite3Introspector) addFields(table *sqlite3Table) {
+	model := table.model
+
+	// pk is the position of the column in the primary key
+	keys := []string{}
+	for position := 1; position <= len(table.columns); position++ {
+		for _, column := range table.columns {
+			if column.pk == position {
+				keys = append(keys, column.name)
+			}
+		}
+	}
+
+	for _, column := range table.columns {
+		for _, fk := range table.foreignKeys {
+			if len(fk.from) == 1 && fk.from[0] == column.name {
+				i.addRelation(table, fk)
+			}
+		}
+
+		fieldType, enum := i.columnType(table, column)
+		field := newField(Identifier(column.name), fieldType, false)
+
+		if column.pk > 0 && len(keys) == 1 {
+			field.Decorators = append(field.Decorators, newDecorator("id"))
+		}
+
+		def := i.columnDefault(table, column, fieldType, enum)
+		if def != nil {
+			field.Decorators = append(field.Decorators, newDecorator("default", def))
+		}
+
+		if !column.notNull && column.pk == 0 {
+			field.Decorators = append(field.Decorators, newDecorator("nullable"))
+		}
+
+		for _, unique := range table.uniques {
+			if len(unique) == 1 && unique[0] == column.name {
+				field.Decorators = append(field.Decorators, newDecorator("unique"))
+				break
+			}
+		}
+
+		if i.naming.Column(field.Identifier.Identifier) != column.name {
+			field.Decorators = append(field.Decorators, newDecorator("map", newArgument("", newString(column.name))))
+		}
+
+		model.AddItem(field)
+	}
+
+	if len(keys) > 1 {
+		model.Decorators = append(model.Decorators, newModelDecorator("id", newArgument("", newList(identifiers(keys)...))))
+	}
+
+	for _, unique := range table.uniques {
+		if len(unique) > 1 {
+			model.Decorators = append(model.Decorators, newModelDecorator("unique", newArgument("", newList(identifiers(unique)...))))
+		}
+	}
+
+	if i.naming.Table(model.Name.Identifier) != table.name {
+		model.Decorators = append(model.Decorators, newModelDecorator("map", newArgument("", newString(table.name))))
+	}
+}
+
+// addRelation adds the relation field of a single column foreign key, named
+// after the column without its Id suffix.
+func (i *Sqlite3Introspector) addRelation(table *sqlite3Table, fk *sqlite3ForeignKey) {
+	target, ok := i.tables[fk.table]
+	if !ok || target.model == nil {
+		return
+	}
+
+	reference := fk.to[0]
+	if reference == "" {
+		for _, column := range target.columns {
+			if column.pk > 0 {
+				reference = column.name
+			}
+		}
+	}
+
+	column := Identifier(fk.from[0])
+	name := strings.TrimSuffix(strings.TrimSuffix(column, "Id"), "_id")
+	if name == column || name == "" {
+		name = utils.Uncapitalize(target.model.Name.Identifier)
+	}
+
+	args := []*ast.Argument{
+		newArgument("field", newIdentValue(column)),
+		newArgument("reference", newIdentValue(Identifier(reference))),
+	}
+
+	if action, ok := sqlite3Actions[fk.onDelete]; ok {
+		args = append(args, newArgument("onDelete", newIdentValue(action)))
+	}
+
+	if action, ok := sqlite3Actions[fk.onUpdate]; ok {
+		args = append(args, newArgument("onUpdate", newIdentValue(action)))
+	}
+
+	field := newField(uniqueName(table, name), target.model.Name.Identifier, false)
+	field.Decorators = append(field.Decorators, newDecorator("relation", args...))
+
+	table.model.AddItem(field)
+}
+
+// addInverseFields adds the list of rows referencing the model to the model
+// they reference.
+func (i *Sqlite3Introspector) addInverseFields(table *sqlite3Table) {
+	for _, fk := range table.foreignKeys {
+		target, ok := i.tables[fk.table]
+		if len(fk.from) != 1 || !ok || target.model == nil {
+			continue
+		}
+
+		name := uniqueName(target, listName(table.model))
+		target.model.AddItem(newField(name, table.model.Name.Identifier, true))
+	}
+}
+
+func (i *Sqlite3Introspector) addManyToMany(table *sqlite3Table) {
+	a, b := table.foreignKeys[0], table.foreignKeys[1]
+	modelA, okA := i.tables[a.table]
+	modelB, okB := i.tables[b.table]
+	if !okA || !okB || modelA.model == nil || modelB.model == nil {
+		return
+	}
+
+	nameA := listName(modelB.model)
+	nameB := listName(modelA.model)
+	if modelA == modelB {
+		// the columns of a self relation are named after its fields
+		nameA = Identifier(strings.TrimSuffix(strings.TrimSuffix(b.from[0], "Id"), "_id"))
+		nameB = Identifier(strings.TrimSuffix(strings.TrimSuffix(a.from[0], "Id"), "_id"))
+	}
+
+	fieldA := newField(uniqueName(modelA, nameA), modelB.model.Name.Identifier, true)
+	fieldB := newField(uniqueName(modelB, nameB), modelA.model.Name.Identifier, true)
+
+	first, second := modelA.model.Name.Identifier, modelB.model.Name.Identifier
+	if first > second {
+		first, second = second, first
+	}
+
+	if table.name != i.naming.Column("_"+first+"To"+second) {
+		relation := newArgument("name", newString(table.name))
+		fieldA.Decorators = append(fieldA.Decorators, newDecorator("relation", relation))
+		fieldB.Decorators = append(fieldB.Decorators, newDecorator("relation", relation))
+	}
+
+	modelA.model.AddItem(fieldA)
+	modelB.model.AddItem(fieldB)
+}
+
+// columnType maps the declared type of a column to a scalar type by the
+// affinity rules of sqlite, a column checked against a list of strings is an
+// enum.
+func (i *Sqlite3Introspector) columnType(table *sqlite3Table, column *sqlite3Column) (string, *ast.Enum) {
+	if values, ok := table.enums[column.name]; ok {
+		enum := i.enum(table, column, values)
+		return enum.Name.Identifier, enum
+	}
+
+	declared := strings.ToUpper(column.declaredType)
+
+	switch {
+	case strings.Contains(declared, "BOOL"):
+		return "bool", nil
+	case strings.Contains(declared, "DATE"), strings.Contains(declared, "TIME"):
+		return "DateTime", nil
+	case strings.Contains(declared, "INT"):
+		return "int", nil
+	case strings.Contains(declared, "CHAR"), strings.Contains(declared, "CLOB"), strings.Contains(declared, "TEXT"):
+		return "string", nil
+	case strings.Contains(declared, "REAL"), strings.Contains(declared, "FLOA"), strings.Contains(declared, "DOUB"), strings.Contains(declared, "NUMERIC"), strings.Contains(declared, "DECIMAL"):
+		return "real", nil
+	}
+
+	return "string", nil
+}
+
+// enum returns the enum of a column, named after the column. Columns checked
+// against the same values share an enum.
+func (i *Sqlite3Introspector) enum(table *sqlite3Table, column *sqlite3Column, values []string) *ast.Enum {
+	name := utils.Capitalize(Identifier(column.name))
+
+	for _, enum := range i.schema.Enums {
+		if enum.Name.Identifier == name && sameValues(enum, values) {
+			return enum
+		}
+	}
+
+	if i.isTaken(name) {
+		name = table.model.Name.Identifier + name
+	}
+
+	enum := newEnum(name)
+	for _, value := range values {
+		enum.AddItem(newEnumItem(Identifier(value), value))
+	}
+	i.schema.Enums = append(i.schema.Enums, enum)
+
+	return enum
+}
+
+func (i *Sqlite3Introspector) isTaken(name string) bool {
+	for _, enum := range i.schema.Enums {
+		if enum.Name.Identifier == name {
+			return true
+		}
+	}
+
+	for _, model := range i.schema.Models {
+		if model.Name.Identifier == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// columnDefault maps the default of a column to the argument of @default,
+// it returns nil for an expression which can not be written in a schema.
+func (i *Sqlite3Introspector) columnDefault(table *sqlite3Table, column *sqlite3Column, fieldType string, enum *ast.Enum) *ast.Argument {
+	if column.pk > 0 && fieldType == "int" && strings.Contains(strings.ToUpper(table.sql), "AUTOINCREMENT") {
+		return newFunction(decorator.FunctionAutoincrement)
+	}
+
+	if !column.defaultValue.Valid {
+		return nil
+	}
+
+	value := strings.TrimSpace(column.defaultValue.String)
+
+	switch {
+	case strings.EqualFold(value, "CURRENT_TIMESTAMP") || strings.Contains(value, "'now'"):
+		if fieldType == "DateTime" {
+			return newFunction(decorator.FunctionNow)
+		}
+	case strings.Contains(value, "randomblob"):
+		if fieldType == "string" {
+			return newFunction(decorator.FunctionUuid)
+		}
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		text := strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		if enum != nil {
+			for _, item := range enum.Items {
+				if item.Value.Value == text {
+					return newArgument("", newString(text))
+				}
+			}
+			return nil
+		}
+		if fieldType == "string" || fieldType == "DateTime" {
+			return newArgument("", newString(text))
+		}
+	default:
+		lex := lexer.NewLexer(value)
+		token := lex.Next()
+		if lex.Next().Type != lexer.TokenTypeEof {
+			return nil
+		}
+
+		switch {
+		case token.Type == lexer.TokenTypeInt && (fieldType == "int" || fieldType == "real"):
+			return newArgument("", ast.NewValue(token))
+		case token.Type == lexer.TokenTypeReal && fieldType == "real":
+			return newArgument("", ast.NewValue(token))
+		case token.Type == lexer.TokenTypeInt && fieldType == "bool":
+			return newArgument("", newBool(token.Literal != "0"))
+		}
+	}
+
+	return nil
+}
+
+// enumValues parses the quoted strings of an IN list.
+func enumValues(list string) ([]string, bool) {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+			return nil, false
+		}
+
+		values = append(values, strings.ReplaceAll(value[1:len(value)-1], "''", "'"))
+	}
+
+	return values, len(values) > 0
+}
+
+func sameValues(enum *ast.Enum, values []string) bool {
+	if len(enum.Items) != len(values) {
+		return false
+	}
+
+	for i, item := range enum.Items {
+		if item.Value.Value != values[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listName returns the name of a list of the model, models named after plural
+// tables like posts are not pluralized twice.
+func listName(model *ast.Model) string {
+	return utils.Uncapitalize(utils.Singular(model.Name.Identifier)) + "s"
+}
+
+// uniqueName returns name, numbered if the model already has a field or
+// column of that name.
+func uniqueName(table *sqlite3Table, name string) string {
+	taken := map[string]struct{}{}
+	for _, item := range table.model.Items {
+		taken[item.Identifier.Identifier] = struct{}{}
+	}
+	for _, column := range table.columns {
+		taken[Identifier(column.name)] = struct{}{}
+	}
+
+	candidate := name
+	for n := 2; ; n++ {
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(n)
+	}
+}
+
+func identifiers(names []string) []string {
+	idents := make([]string, len(names))
+	for i, name := range names {
+		idents[i] = Identifier(name)
+	}
+
+	return idents
+}
@@ -0,0 +1,341 @@
+package introspect_test
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path"
+	"slices"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/generator"
+	"github.com/gophoria/gophoria/pkg/introspect"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/migration"
+	"github.com/gophoria/gophoria/pkg/parser"
+	"github.com/gophoria/gophoria/pkg/printer"
+)
+
+func TestSqlite3Introspector(t *testing.T) {
+	db, err := sql.Open("sqlite3", path.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+CREATE TABLE User (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL UNIQUE, name TEXT, role TEXT NOT NULL DEFAULT 'admin' CHECK (role IN ('admin', 'user')), createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, active BOOLEAN NOT NULL DEFAULT 1);
+CREATE TABLE Post (id TEXT NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), title TEXT NOT NULL, score REAL, authorId INTEGER NOT NULL REFERENCES User(id) ON DELETE CASCADE, UNIQUE (title, authorId));
+CREATE TABLE Tag (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+CREATE TABLE _PostToTag (postId TEXT NOT NULL REFERENCES Post(id), tagId INTEGER NOT NULL REFERENCES Tag(id), PRIMARY KEY (postId, tagId));
+CREATE TABLE "order items" ("order id" INTEGER NOT NULL, line INTEGER NOT NULL, PRIMARY KEY ("order id", line));
+CREATE TABLE _gophoria_migrations (name TEXT PRIMARY KEY);
+`)
+	if err != nil {
+		t.Fatalf("unable to create tables: %s", err.Error())
+	}
+
+	introspector, err := introspect.GetIntrospector("sqlite3")
+	if err != nil {
+		t.Fatalf("unable to find introspector: %s", err.Error())
+	}
+
+	schema, err := introspector.Introspect(db, decorator.NamingOf(nil))
+	if err != nil {
+		t.Fatalf("unable to introspect database: %s", err.Error())
+	}
+
+	expected := `enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model User {
+  id        int      @id @default(autoincrement())
+  email     string   @unique
+  name      string   @nullable
+  role      Role     @default("admin")
+  createdAt DateTime @default(now())
+  active    bool     @default(true)
+  posts     Post[]
+}
+
+model Post {
+  id       string @id @default(uuid())
+  title    string
+  score    real   @nullable
+  author   User   @relation(field: authorId, reference: id, onDelete: Cascade)
+  authorId int
+  tags     Tag[]
+
+  @@unique([title, authorId])
+}
+
+model Tag {
+  id    int @id
+  name  string
+  posts Post[]
+}
+
+model order_items {
+  order_id int @map("order id")
+  line     int
+
+  @@id([order_id, line])
+  @@map("order items")
+}
+`
+
+	var buf bytes.Buffer
+	err = printer.NewPrinter(&buf).Print(schema)
+	if err != nil {
+		t.Fatalf("unable to print schema: %s", err.Error())
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	p := parser.NewParser(lexer.NewLexer(buf.String()))
+	parsed, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unable to parse printed schema: %s", err.Error())
+	}
+
+	_, err = analyzer.NewAnalyzer(parsed).Analyze()
+	if err != nil {
+		t.Fatalf("printed schema is invalid: %s", err.Error())
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"User":        "User",
+		"order items": "order_items",
+		"2fa":         "fa",
+		"model":       "model_",
+		"--":          "unnamed",
+	}
+
+	for name, expected := range tests {
+		actual := introspect.Identifier(name)
+		if actual != expected {
+			t.Fatalf("expected identifier of %s to be %s, got %s", name, expected, actual)
+		}
+	}
+}
+
+func TestSqlite3IntrospectorPluralTables(t *testing.T) {
+	db, err := sql.Open("sqlite3", path.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+CREATE TABLE users (id INTEGER PRIMARY KEY);
+CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users(id));
+CREATE TABLE tags (id INTEGER PRIMARY KEY);
+CREATE TABLE tags_posts (tag_id INTEGER NOT NULL REFERENCES tags(id), post_id INTEGER NOT NULL REFERENCES posts(id), PRIMARY KEY (tag_id, post_id));
+`)
+	if err != nil {
+		t.Fatalf("unable to create tables: %s", err.Error())
+	}
+
+	introspector, err := introspect.GetIntrospector("sqlite3")
+	if err != nil {
+		t.Fatalf("unable to find introspector: %s", err.Error())
+	}
+
+	schema, err := introspector.Introspect(db, decorator.NamingOf(nil))
+	if err != nil {
+		t.Fatalf("unable to introspect database: %s", err.Error())
+	}
+
+	expected := `model users {
+  id    int @id
+  posts posts[]
+}
+
+model posts {
+  id         int   @id
+  user       users @relation(field: user_id, reference: id)
+  user_id    int
+  tags_posts tags_posts[]
+}
+
+model tags {
+  id         int @id
+  tags_posts tags_posts[]
+}
+
+model tags_posts {
+  tag     tags  @relation(field: tag_id, reference: id)
+  tag_id  int
+  post    posts @relation(field: post_id, reference: id)
+  post_id int
+
+  @@id([tag_id, post_id])
+}
+`
+
+	var buf bytes.Buffer
+	err = printer.NewPrinter(&buf).Print(schema)
+	if err != nil {
+		t.Fatalf("unable to print schema: %s", err.Error())
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestSqlite3IntrospectorNaming(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", path.Join(dir, "pulled.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+CREATE TABLE users (id INTEGER PRIMARY KEY, full_name TEXT NOT NULL, nickName TEXT);
+CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users(id));
+CREATE TABLE tags (id INTEGER PRIMARY KEY);
+CREATE TABLE post_tags (posts_id INTEGER NOT NULL REFERENCES posts(id), tags_id INTEGER NOT NULL REFERENCES tags(id), PRIMARY KEY (posts_id, tags_id));
+`)
+	if err != nil {
+		t.Fatalf("unable to create tables: %s", err.Error())
+	}
+
+	config, err := parser.NewParser(lexer.NewLexer(`
+db {
+  provider = "sqlite3"
+  url = "pulled.db"
+  naming = "snake_plural"
+}`)).Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	introspector, err := introspect.GetIntrospector("sqlite3")
+	if err != nil {
+		t.Fatalf("unable to find introspector: %s", err.Error())
+	}
+
+	schema, err := introspector.Introspect(db, decorator.NamingOf(config))
+	if err != nil {
+		t.Fatalf("unable to introspect database: %s", err.Error())
+	}
+	schema.Config = config.Config
+
+	expected := `db {
+  provider = "sqlite3"
+  url = "pulled.db"
+  naming = "snake_plural"
+}
+
+model users {
+  id        int    @id
+  full_name string
+  nickName  string @nullable @map("nickName")
+  posts     posts[]
+
+  @@map("users")
+}
+
+model posts {
+  id      int    @id
+  user    users  @relation(field: user_id, reference: id)
+  user_id int
+  tags    tags[] @relation(name: "post_tags")
+
+  @@map("posts")
+}
+
+model tags {
+  id    int     @id
+  posts posts[] @relation(name: "post_tags")
+
+  @@map("tags")
+}
+`
+
+	var buf bytes.Buffer
+	err = printer.NewPrinter(&buf).Print(schema)
+	if err != nil {
+		t.Fatalf("unable to print schema: %s", err.Error())
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	// the tables generated from the pulled schema are the pulled tables
+	err = generator.NewSqlite3Generator().GenerateMigration(migration.Diff(nil, schema), &generator.GeneratorConfig{WorkingDir: dir}, "1_init")
+	if err != nil {
+		t.Fatalf("generator error: %s", err.Error())
+	}
+
+	up, err := os.ReadFile(path.Join(dir, "migrations", "1_init.up.sql"))
+	if err != nil {
+		t.Fatalf("unable to read migration: %s", err.Error())
+	}
+
+	generated, err := sql.Open("sqlite3", path.Join(dir, "generated.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer generated.Close()
+
+	_, err = generated.Exec(string(up))
+	if err != nil {
+		t.Fatalf("unable to run migration: %s", err.Error())
+	}
+
+	tables := func(db *sql.DB) []string {
+		rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+		if err != nil {
+			t.Fatalf("unable to read tables: %s", err.Error())
+		}
+		defer rows.Close()
+
+		names := []string{}
+		for rows.Next() {
+			name := ""
+			err = rows.Scan(&name)
+			if err != nil {
+				t.Fatalf("unable to read tables: %s", err.Error())
+			}
+			names = append(names, name)
+		}
+
+		return names
+	}
+
+	pulled, regenerated := tables(db), tables(generated)
+	if !slices.Equal(pulled, regenerated) {
+		t.Fatalf("expected tables %v, got %v", pulled, regenerated)
+	}
+
+	repulled, err := introspector.Introspect(generated, decorator.NamingOf(config))
+	if err != nil {
+		t.Fatalf("unable to introspect database: %s", err.Error())
+	}
+	repulled.Config = config.Config
+
+	var again bytes.Buffer
+	err = printer.NewPrinter(&again).Print(repulled)
+	if err != nil {
+		t.Fatalf("unable to print schema: %s", err.Error())
+	}
+
+	if again.String() != buf.String() {
+		t.Fatalf("expected the generated tables to be pulled as:\n%s\ngot:\n%s", buf.String(), again.String())
+	}
+}
@@ -1,13 +1,21 @@
 package lexer
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
+// Lexer reads the input rune by rune. Rows and columns are zero based and
+// count runes, a tab is a single column and \r\n ends a line like \n.
 type Lexer struct {
+	file         string
 	input        string
 	position     int
 	peekPosition int
 
-	ch  byte
+	ch  rune
 	row int
 	col int
 }
@@ -19,11 +27,13 @@ var keywordsMap = map[string]TokenType{
 	"ui":       TokenTypeUi,
 	"true":     TokenTypeTrue,
 	"false":    TokenTypeFalse,
+	"null":     TokenTypeNull,
 	"int":      TokenTypeTInt,
 	"real":     TokenTypeTReal,
 	"string":   TokenTypeTString,
 	"bool":     TokenTypeTBool,
 	"DateTime": TokenTypeTDateTime,
+	"import":   TokenTypeImport,
 }
 
 func NewLexer(input string) *Lexer {
@@ -41,7 +51,23 @@ func NewLexer(input string) *Lexer {
 	return &lexer
 }
 
+// NewFileLexer creates a lexer for the content of a file, its tokens are
+// marked with the file name.
+func NewFileLexer(file string, input string) *Lexer {
+	lexer := NewLexer(input)
+	lexer.file = file
+
+	return lexer
+}
+
 func (l *Lexer) Next() *Token {
+	tok := l.next()
+	tok.File = l.file
+
+	return tok
+}
+
+func (l *Lexer) next() *Token {
 	var tok *Token = nil
 
 	l.skipWhitespaces()
@@ -78,13 +104,34 @@ func (l *Lexer) Next() *Token {
 		tok = NewToken(TokenTypeComma, ",", l.row, l.col)
 		break
 	case '@':
+		if l.peekChar() == '@' {
+			tok = NewToken(TokenTypeModelDecorator, "@@", l.row, l.col)
+			l.readChar()
+			break
+		}
 		tok = NewToken(TokenTypeDecorator, "@", l.row, l.col)
 		break
-	case '"':
+	case '/':
+		if l.peekChar() == '/' {
+			row := l.row
+			col := l.col
+			tokenType, literal := l.readComment()
+			tok = NewToken(tokenType, literal, row, col)
+			return tok
+		}
+		break
+	case '"', '`':
 		row := l.row
 		col := l.col
-		literal := l.readString()
-		tok = NewToken(TokenTypeString, literal, row, col)
+		tokenType, literal, raw := l.readString()
+		tok = NewToken(tokenType, literal, row, col)
+		tok.Raw = raw
+		return tok
+	case utf8.RuneError:
+		if l.isInvalidRune() {
+			tok = NewToken(TokenTypeError, "invalid UTF-8 encoding", l.row, l.col)
+			tok.Raw = l.input[l.position:l.peekPosition]
+		}
 		break
 	}
 
@@ -95,11 +142,15 @@ func (l *Lexer) Next() *Token {
 			tokenType, literal := l.readIdentifier()
 			tok = NewToken(tokenType, literal, row, col)
 			return tok
-		} else if l.isNumber(l.ch) {
+		} else if l.isNumber(l.ch) || (l.ch == '-' && l.isNumber(l.peekChar())) {
 			row := l.row
 			col := l.col
-			literal := l.readNumber()
-			tok = NewToken(TokenTypeInt, literal, row, col)
+			tokenType, literal := l.readNumber()
+			tok = NewToken(tokenType, literal, row, col)
+			if tokenType == TokenTypeError {
+				tok.Literal = "invalid number " + literal
+				tok.Raw = literal
+			}
 			return tok
 		}
 	}
@@ -114,38 +165,98 @@ func (l *Lexer) Next() *Token {
 }
 
 func (l *Lexer) readChar() {
-	if l.peekPosition >= len(l.input) {
-		l.ch = 0
+	if l.ch == '\n' {
+		l.row++
+		l.col = 0
 	} else {
-		l.ch = l.input[l.peekPosition]
+		l.col++
 	}
 
 	l.position = l.peekPosition
-	l.peekPosition++
-	l.col++
+	if l.position >= len(l.input) {
+		l.ch = 0
+		return
+	}
+
+	ch, width := utf8.DecodeRuneInString(l.input[l.position:])
+	l.ch = ch
+	l.peekPosition = l.position + width
+}
+
+func (l *Lexer) peekChar() rune {
+	if l.peekPosition >= len(l.input) {
+		return 0
+	}
+
+	ch, _ := utf8.DecodeRuneInString(l.input[l.peekPosition:])
+	return ch
+}
+
+// isInvalidRune tells whether the current rune is an encoding error rather
+// than an U+FFFD written in the input.
+func (l *Lexer) isInvalidRune() bool {
+	return l.peekPosition-l.position == 1
 }
 
-func (l *Lexer) readString() string {
-	var sb strings.Builder
+func (l *Lexer) readComment() (TokenType, string) {
+	start := l.position
+
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+
+	literal := strings.TrimRight(l.input[start:l.position], " \t\r")
+	if strings.HasPrefix(literal, "///") && !strings.HasPrefix(literal, "////") {
+		return TokenTypeDocComment, literal
+	}
+
+	return TokenTypeComment, literal
+}
+
+// readString reads a string in double quotes, which may contain escapes
+// like \" or \u00e9, or a raw string in backticks, which may span lines.
+// It returns the value of the string and its source text.
+func (l *Lexer) readString() (TokenType, string, string) {
+	start := l.position
+	quote := l.ch
 
 	l.readChar()
-	for l.ch != '"' {
-		sb.WriteByte(l.ch)
+	for l.ch != quote {
+		if l.ch == 0 || (quote == '"' && l.ch == '\n') {
+			return TokenTypeError, "unterminated string", strings.TrimRight(l.input[start:l.position], "\r")
+		}
+
+		if quote == '"' && l.ch == '\\' {
+			l.readChar()
+			if l.ch == 0 || l.ch == '\n' {
+				continue
+			}
+		}
 		l.readChar()
 	}
+	l.readChar()
+
+	raw := l.input[start:l.position]
+	if quote == '`' {
+		return TokenTypeString, strings.ReplaceAll(raw[1:len(raw)-1], "\r", ""), raw
+	}
 
-	return sb.String()
+	literal, err := strconv.Unquote(raw)
+	if err != nil {
+		return TokenTypeError, "invalid escape sequence in string", raw
+	}
+
+	return TokenTypeString, literal, raw
 }
 
 func (l *Lexer) readIdentifier() (TokenType, string) {
-	var sb strings.Builder
+	start := l.position
 
 	for l.isLetter(l.ch) || l.isNumber(l.ch) || l.ch == '_' {
-		sb.WriteByte(l.ch)
 		l.readChar()
 	}
 
-	literal := sb.String()
+	literal := l.input[start:l.position]
 	tokenType, ok := keywordsMap[literal]
 	if !ok {
 		tokenType = TokenTypeIdent
@@ -154,32 +265,76 @@ func (l *Lexer) readIdentifier() (TokenType, string) {
 	return tokenType, literal
 }
 
-func (l *Lexer) readNumber() string {
-	var sb strings.Builder
+// readNumber reads an int, optionally negative or hexadecimal like 0x1f,
+// or a real with a fraction or an exponent like 3.14 or 1e-3.
+func (l *Lexer) readNumber() (TokenType, string) {
+	start := l.position
 
-	for l.isNumber(l.ch) {
-		sb.WriteByte(l.ch)
+	if l.ch == '-' {
 		l.readChar()
 	}
 
-	return sb.String()
-}
+	if l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X') {
+		l.readChar()
+		l.readChar()
 
-func (l *Lexer) skipWhitespaces() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' {
-		if l.ch == '\n' {
-			l.row++
-			l.col = 0
+		digits := l.position
+		for l.isHexNumber(l.ch) {
+			l.readChar()
+		}
+
+		if l.position == digits {
+			return TokenTypeError, l.input[start:l.position]
+		}
+
+		return TokenTypeInt, l.input[start:l.position]
+	}
+
+	tokenType := TokenTypeInt
+	l.readDigits()
+
+	if l.ch == '.' && l.isNumber(l.peekChar()) {
+		tokenType = TokenTypeReal
+		l.readChar()
+		l.readDigits()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = TokenTypeReal
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
 		}
 
+		if !l.isNumber(l.ch) {
+			return TokenTypeError, l.input[start:l.position]
+		}
+		l.readDigits()
+	}
+
+	return tokenType, l.input[start:l.position]
+}
+
+func (l *Lexer) readDigits() {
+	for l.isNumber(l.ch) {
 		l.readChar()
 	}
 }
 
-func (l *Lexer) isNumber(ch byte) bool {
+func (l *Lexer) skipWhitespaces() {
+	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) isNumber(ch rune) bool {
 	return ch >= '0' && ch <= '9'
 }
 
-func (l *Lexer) isLetter(ch byte) bool {
-	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+func (l *Lexer) isHexNumber(ch rune) bool {
+	return l.isNumber(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+func (l *Lexer) isLetter(ch rune) bool {
+	return unicode.IsLetter(ch)
 }
@@ -142,3 +142,120 @@ model Post {
 		}
 	}
 }
+
+func TestLexerComments(t *testing.T) {
+	input := `
+// line comment
+/// doc comment
+model User { // trailing comment
+  //// not a doc comment
+  id string
+}`
+
+	expected := []*lexer.Token{
+		lexer.NewToken(lexer.TokenTypeComment, "// line comment", 0, 0),
+		lexer.NewToken(lexer.TokenTypeDocComment, "/// doc comment", 0, 0),
+		lexer.NewToken(lexer.TokenTypeModel, "model", 0, 0),
+		lexer.NewToken(lexer.TokenTypeIdent, "User", 0, 0),
+		lexer.NewToken(lexer.TokenTypeLBrace, "{", 0, 0),
+		lexer.NewToken(lexer.TokenTypeComment, "// trailing comment", 0, 0),
+		lexer.NewToken(lexer.TokenTypeComment, "//// not a doc comment", 0, 0),
+		lexer.NewToken(lexer.TokenTypeIdent, "id", 0, 0),
+		lexer.NewToken(lexer.TokenTypeTString, "string", 0, 0),
+		lexer.NewToken(lexer.TokenTypeRBrace, "}", 0, 0),
+		lexer.NewToken(lexer.TokenTypeEof, "", 0, 0),
+	}
+
+	lexer := lexer.NewLexer(input)
+
+	for _, exp := range expected {
+		tok := lexer.Next()
+
+		if tok.Type != exp.Type {
+			t.Fatalf("expected token type %v but got %v", exp.Type, tok.Type)
+		}
+
+		if tok.Literal != exp.Literal {
+			t.Fatalf("expected token literal %v but got %v", exp.Literal, tok.Literal)
+		}
+	}
+}
+
+func TestLexerLiterals(t *testing.T) {
+	input := "\"say \\\"hi\\\"\\n\" \"caf\\u00e9\" `C:\\path\r\nnext` 42 -1 3.14 -0.5 1e3 2.5E-2 0x1F \"café\" 1."
+
+	expected := []*lexer.Token{
+		lexer.NewToken(lexer.TokenTypeString, "say \"hi\"\n", 0, 0),
+		lexer.NewToken(lexer.TokenTypeString, "café", 0, 15),
+		lexer.NewToken(lexer.TokenTypeString, "C:\\path\nnext", 0, 27),
+		lexer.NewToken(lexer.TokenTypeInt, "42", 1, 6),
+		lexer.NewToken(lexer.TokenTypeInt, "-1", 1, 9),
+		lexer.NewToken(lexer.TokenTypeReal, "3.14", 1, 12),
+		lexer.NewToken(lexer.TokenTypeReal, "-0.5", 1, 17),
+		lexer.NewToken(lexer.TokenTypeReal, "1e3", 1, 22),
+		lexer.NewToken(lexer.TokenTypeReal, "2.5E-2", 1, 26),
+		lexer.NewToken(lexer.TokenTypeInt, "0x1F", 1, 33),
+		lexer.NewToken(lexer.TokenTypeString, "café", 1, 38),
+		lexer.NewToken(lexer.TokenTypeInt, "1", 1, 45),
+		lexer.NewToken(lexer.TokenTypeIllegal, ".", 1, 46),
+		lexer.NewToken(lexer.TokenTypeEof, "", 1, 47),
+	}
+
+	lexer := lexer.NewLexer(input)
+
+	for _, exp := range expected {
+		tok := lexer.Next()
+
+		if tok.Type != exp.Type || tok.Literal != exp.Literal || tok.Row != exp.Row || tok.Col != exp.Col {
+			t.Fatalf("expected token %s but got %s", exp, tok)
+		}
+	}
+}
+
+func TestLexerPositions(t *testing.T) {
+	input := "model Café {\r\n\tid\tint // é\r\n}"
+
+	expected := []*lexer.Token{
+		lexer.NewToken(lexer.TokenTypeModel, "model", 0, 0),
+		lexer.NewToken(lexer.TokenTypeIdent, "Café", 0, 6),
+		lexer.NewToken(lexer.TokenTypeLBrace, "{", 0, 11),
+		lexer.NewToken(lexer.TokenTypeIdent, "id", 1, 1),
+		lexer.NewToken(lexer.TokenTypeTInt, "int", 1, 4),
+		lexer.NewToken(lexer.TokenTypeComment, "// é", 1, 8),
+		lexer.NewToken(lexer.TokenTypeRBrace, "}", 2, 0),
+		lexer.NewToken(lexer.TokenTypeEof, "", 2, 1),
+	}
+
+	lexer := lexer.NewLexer(input)
+
+	for _, exp := range expected {
+		tok := lexer.Next()
+
+		if tok.Type != exp.Type || tok.Literal != exp.Literal || tok.Row != exp.Row || tok.Col != exp.Col {
+			t.Fatalf("expected token %s but got %s", exp, tok)
+		}
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	input := "\"unterminated\nid \"bad \\q escape\" 0x `open"
+
+	expected := []*lexer.Token{
+		lexer.NewToken(lexer.TokenTypeError, "unterminated string", 0, 0),
+		lexer.NewToken(lexer.TokenTypeIdent, "id", 1, 0),
+		lexer.NewToken(lexer.TokenTypeError, "invalid escape sequence in string", 1, 3),
+		lexer.NewToken(lexer.TokenTypeError, "invalid number 0x", 1, 19),
+		lexer.NewToken(lexer.TokenTypeError, "unterminated string", 1, 22),
+		lexer.NewToken(lexer.TokenTypeEof, "", 1, 27),
+	}
+
+	lexer := lexer.NewLexer(input)
+
+	for _, exp := range expected {
+		tok := lexer.Next()
+
+		if tok.Type != exp.Type || tok.Literal != exp.Literal || tok.Row != exp.Row || tok.Col != exp.Col {
+			t.Fatalf("expected token %s but got %s", exp, tok)
+		}
+	}
+}
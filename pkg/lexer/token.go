@@ -15,6 +15,10 @@ const (
 	TokenTypeInt
 	TokenTypeString
 
+	// comments
+	TokenTypeComment
+	TokenTypeDocComment
+
 	// operators
 	TokenTypeAssign
 
@@ -32,6 +36,7 @@ const (
 	TokenTypeComma
 
 	TokenTypeDecorator
+	TokenTypeModelDecorator
 
 	// keywords
 	TokenTypeEnum
@@ -47,14 +52,29 @@ const (
 	TokenTypeTString
 	TokenTypeTBool
 	TokenTypeTDateTime
+
+	// added after the types so the types stored in snapshots keep their
+	// values
+	TokenTypeImport
+	TokenTypeReal
+	// TokenTypeError is a token the lexer could not read, like an
+	// unterminated string, its literal describes the error.
+	TokenTypeError
+	TokenTypeNull
 )
 
 type Token struct {
 	Type    TokenType
 	Literal string
-
-	Row int
-	Col int
+	// Raw is the source text of strings and error tokens, when it differs
+	// from the literal, e.g. a string with its quotes and escapes.
+	Raw string `json:"-"`
+
+	// File is the source file of the token, empty if the input was not read
+	// from a file.
+	File string `json:",omitempty"`
+	Row  int
+	Col  int
 }
 
 func NewToken(tokenType TokenType, literal string, row int, col int) *Token {
@@ -0,0 +1,229 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+)
+
+var (
+	completionModelDecorator = regexp.MustCompile(`@@\w*$`)
+	completionDecorator      = regexp.MustCompile(`@\w*$`)
+	completionCall           = regexp.MustCompile(`(@@?)(\w+)\(([^()]*)$`)
+	completionNamedArgument  = regexp.MustCompile(`^\s*(\w+)\s*:\s*\w*$`)
+	completionType           = regexp.MustCompile(`^\s*\w+\s+\w*$`)
+	completionTopLevel       = regexp.MustCompile(`^\w*$`)
+	completionBlock          = regexp.MustCompile(`^\s*(model|enum|db|ui)\b`)
+)
+
+var scalarTypes = []string{"int", "real", "bool", "string", "DateTime"}
+
+// Complete returns what can be written at the position: type names after
+// the name of a field, decorator names after @ and the arguments and values
+// of a decorator inside its parentheses.
+func (d *Document) Complete(pos Position) []CompletionItem {
+	lines := strings.Split(d.Text, "\n")
+	if pos.Line >= len(lines) {
+		return []CompletionItem{}
+	}
+
+	line := []rune(lines[pos.Line])
+	prefix := string(line[:min(d.column(pos), len(line))])
+	block := blockAt(lines, pos.Line)
+
+	if block == "" {
+		if completionTopLevel.MatchString(prefix) {
+			return keywordItems()
+		}
+
+		return []CompletionItem{}
+	}
+
+	if block != "model" {
+		return []CompletionItem{}
+	}
+
+	if match := completionCall.FindStringSubmatch(prefix); match != nil {
+		return d.completeArguments(pos.Line, match[1], match[2], match[3])
+	}
+
+	if completionModelDecorator.MatchString(prefix) {
+		return decoratorItems(decorator.ModelNames(), decorator.LookupModel)
+	}
+
+	if completionDecorator.MatchString(prefix) {
+		return decoratorItems(decorator.Names(), decorator.Lookup)
+	}
+
+	if completionType.MatchString(prefix) {
+		return d.typeItems()
+	}
+
+	return []CompletionItem{}
+}
+
+// completeArguments completes the arguments of a decorator call, args is
+// the text from the opening parenthesis to the position.
+func (d *Document) completeArguments(line int, prefix string, name string, args string) []CompletionItem {
+	lookup := decorator.Lookup
+	if prefix == "@@" {
+		lookup = decorator.LookupModel
+	}
+
+	spec, ok := lookup(name)
+	if !ok {
+		return []CompletionItem{}
+	}
+
+	model, _ := d.modelAt(line)
+
+	// inside a list only field names are written
+	if strings.Count(args, "[") > strings.Count(args, "]") {
+		return fieldItems(model)
+	}
+
+	segments := strings.Split(args, ",")
+	segment := segments[len(segments)-1]
+
+	if match := completionNamedArgument.FindStringSubmatch(segment); match != nil {
+		for _, argSpec := range spec.Arguments {
+			if argSpec.Name == match[1] {
+				return d.valueItems(argSpec, model, line)
+			}
+		}
+
+		return []CompletionItem{}
+	}
+
+	items := []CompletionItem{}
+	for _, argSpec := range spec.Arguments {
+		items = append(items, CompletionItem{
+			Label:      argSpec.Name,
+			Kind:       CompletionItemKindProperty,
+			Detail:     argSpec.Kind.String(),
+			InsertText: argSpec.Name + ": ",
+		})
+	}
+
+	// the first argument can be passed by position
+	if len(segments) == 1 && len(spec.Arguments) > 0 {
+		items = append(items, d.valueItems(spec.Arguments[0], model, line)...)
+	}
+
+	return items
+}
+
+func (d *Document) valueItems(argSpec *decorator.ArgumentSpec, model *ast.Model, line int) []CompletionItem {
+	switch argSpec.Name {
+	case "field":
+		return fieldItems(model)
+	case "reference":
+		field, ok := fieldAt(model, line)
+		if !ok {
+			return []CompletionItem{}
+		}
+
+		target, ok := d.symbols.Resolve(field.DeclarationType)
+		if !ok || target.Model == nil {
+			return []CompletionItem{}
+		}
+
+		return fieldItems(target.Model)
+	}
+
+	items := []CompletionItem{}
+	for _, value := range argSpec.Values {
+		item := CompletionItem{Label: value, Kind: CompletionItemKindValue}
+		if argSpec.Kind&decorator.ArgumentKindFunction != 0 {
+			item.Kind = CompletionItemKindFunction
+			item.InsertText = value + "()"
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func (d *Document) typeItems() []CompletionItem {
+	items := []CompletionItem{}
+	for _, name := range scalarTypes {
+		items = append(items, CompletionItem{Label: name, Kind: CompletionItemKindKeyword})
+	}
+
+	for _, enum := range d.ast.Enums {
+		items = append(items, CompletionItem{Label: enum.Name.Identifier, Kind: CompletionItemKindEnum})
+	}
+
+	for _, model := range d.ast.Models {
+		items = append(items, CompletionItem{Label: model.Name.Identifier, Kind: CompletionItemKindClass})
+	}
+
+	return items
+}
+
+func decoratorItems(names []string, lookup func(string) (*decorator.Spec, bool)) []CompletionItem {
+	items := []CompletionItem{}
+	for _, name := range names {
+		spec, _ := lookup(name)
+		items = append(items, CompletionItem{Label: name, Kind: CompletionItemKindFunction, Detail: spec.Targets.String()})
+	}
+
+	return items
+}
+
+func fieldItems(model *ast.Model) []CompletionItem {
+	items := []CompletionItem{}
+	if model == nil {
+		return items
+	}
+
+	for _, item := range model.Items {
+		items = append(items, CompletionItem{Label: item.Identifier.Identifier, Kind: CompletionItemKindField, Detail: item.DeclarationType.String()})
+	}
+
+	return items
+}
+
+func keywordItems() []CompletionItem {
+	items := []CompletionItem{}
+	for _, keyword := range []string{"model", "enum", "db", "ui"} {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionItemKindKeyword})
+	}
+
+	return items
+}
+
+func fieldAt(model *ast.Model, line int) (*ast.Declaration, bool) {
+	if model == nil {
+		return nil, false
+	}
+
+	for _, item := range model.Items {
+		if item.Identifier.Token.Row == line {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+// blockAt returns the keyword of the block the line is in, or an empty
+// string outside of blocks. It reads the text as the block may not parse
+// while it is being written.
+func blockAt(lines []string, line int) string {
+	for i := line - 1; i >= 0; i-- {
+		text := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(text), "}") {
+			return ""
+		}
+
+		if match := completionBlock.FindStringSubmatch(text); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,108 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Conn reads and writes json-rpc messages framed by a Content-Length
+// header, the base protocol of the language server protocol.
+type Conn struct {
+	reader *bufio.Reader
+	writer io.Writer
+	mu     sync.Mutex
+}
+
+func NewConn(reader io.Reader, writer io.Writer) *Conn {
+	c := Conn{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+
+	return &c
+}
+
+func (c *Conn) Read() (*Message, error) {
+	length := -1
+
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %s", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	_, err := io.ReadFull(c.reader, body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := Message{}
+	err = json.Unmarshal(body, &msg)
+	if err != nil {
+		return nil, &ResponseError{Code: CodeParseError, Message: err.Error()}
+	}
+
+	return &msg, nil
+}
+
+func (c *Conn) Write(msg *Message) error {
+	msg.JSONRPC = "2.0"
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err = fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// Reply answers the request with the given id, result may be nil.
+func (c *Conn) Reply(id *json.RawMessage, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return c.Write(&Message{ID: id, Result: data})
+}
+
+func (c *Conn) ReplyError(id *json.RawMessage, err *ResponseError) error {
+	return c.Write(&Message{ID: id, Error: err})
+}
+
+func (c *Conn) Notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return c.Write(&Message{Method: method, Params: data})
+}
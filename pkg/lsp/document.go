@@ -0,0 +1,411 @@
+package lsp
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+type referenceKind int
+
+const (
+	referenceSymbol referenceKind = iota
+	referenceField
+)
+
+// reference is a name in the document, either where a model, enum or field
+// is declared or where it is used.
+type reference struct {
+	token      *lexer.Token
+	kind       referenceKind
+	model      string
+	name       string
+	definition bool
+	// field is the declaration on the line of the reference.
+	field *ast.Declaration
+}
+
+func (r *reference) matches(other *reference) bool {
+	return r.kind == other.kind && r.model == other.model && r.name == other.name
+}
+
+func (r *reference) contains(pos Position) bool {
+	start := diagnostic.Start(r.token)
+	end := diagnostic.End(r.token)
+
+	return pos.Line == start.Row && pos.Character >= start.Col && pos.Character <= end.Col
+}
+
+// Document is an open gophoria file, parsed and analyzed on every change.
+// The files it imports are read from disk, so names declared in them
+// resolve.
+type Document struct {
+	URI  string
+	Text string
+	// path is the file of a file:// uri.
+	path string
+
+	ast         *ast.Ast
+	symbols     *analyzer.SymbolTable
+	parsed      bool
+	diagnostics diagnostic.Diagnostics
+	references  []*reference
+}
+
+func NewDocument(uri string, text string) *Document {
+	d := Document{
+		URI:  uri,
+		Text: text,
+		path: filePath(uri),
+	}
+
+	d.analyze()
+
+	return &d
+}
+
+// analyze parses the document and its imports and, if there are no syntax
+// errors, checks them with the analyzer. Analyzing a partially parsed
+// document would report errors for every item the parser skipped.
+func (d *Document) analyze() {
+	var err error
+	if d.path != "" {
+		loader := parser.NewLoader()
+		loader.ReadFile = d.readFile
+		d.ast, err = loader.Load(d.path)
+	} else {
+		d.ast, err = parser.NewParser(lexer.NewLexer(d.Text)).Parse()
+	}
+
+	d.diagnostics = diagnostic.Diagnostics{}
+	errors.As(err, &d.diagnostics)
+	if d.ast == nil {
+		d.ast = ast.NewAst()
+	}
+	d.parsed = !d.diagnostics.HasErrors()
+
+	a := analyzer.NewAnalyzer(d.ast)
+	d.symbols, _ = a.Analyze()
+	if d.parsed {
+		d.diagnostics = append(d.diagnostics, a.Diagnostics()...)
+	}
+
+	d.collectReferences()
+}
+
+// readFile reads the text of the document itself and other files from disk.
+func (d *Document) readFile(name string) ([]byte, error) {
+	if filepath.Clean(name) == d.path {
+		return []byte(d.Text), nil
+	}
+
+	return os.ReadFile(name)
+}
+
+// Diagnostics returns the problems of the document in the protocol format.
+func (d *Document) Diagnostics() []Diagnostic {
+	diags := []Diagnostic{}
+
+	for _, diag := range d.diagnostics {
+		if diag.File != d.path {
+			continue
+		}
+
+		message := diag.Message
+		if diag.Hint != "" {
+			message += "\n" + diag.Hint
+		}
+
+		diags = append(diags, Diagnostic{
+			Range: Range{
+				Start: d.position(diag.File, diag.Start.Row, diag.Start.Col),
+				End:   d.position(diag.File, diag.End.Row, diag.End.Col),
+			},
+			Severity: int(diag.Severity) + SeverityError,
+			Code:     diag.Code,
+			Source:   "gophoria",
+			Message:  message,
+		})
+	}
+
+	return diags
+}
+
+func (d *Document) collectReferences() {
+	d.references = []*reference{}
+
+	for _, enum := range d.ast.Enums {
+		d.addReference(&reference{token: enum.Name.Token, kind: referenceSymbol, name: enum.Name.Identifier, definition: true})
+	}
+
+	for _, model := range d.ast.Models {
+		modelName := model.Name.Identifier
+		d.addReference(&reference{token: model.Name.Token, kind: referenceSymbol, name: modelName, definition: true})
+
+		for _, item := range model.Items {
+			d.addReference(&reference{token: item.Identifier.Token, kind: referenceField, model: modelName, name: item.Identifier.Identifier, definition: true, field: item})
+
+			declType := item.DeclarationType
+			if declType.Type == ast.VariableTypeObject {
+				d.addReference(&reference{token: declType.Token, kind: referenceSymbol, name: declType.Name, field: item})
+			}
+
+			relation := decorator.ForField(item).Relation
+			if relation == nil {
+				continue
+			}
+
+			args, _ := decorator.Bind(relation.Decorator)
+			if arg, ok := args["field"]; ok && arg.Value != nil {
+				d.addReference(&reference{token: arg.Value.Token, kind: referenceField, model: modelName, name: arg.Value.Value, field: item})
+			}
+			if arg, ok := args["reference"]; ok && arg.Value != nil {
+				d.addReference(&reference{token: arg.Value.Token, kind: referenceField, model: declType.Name, name: arg.Value.Value, field: item})
+			}
+		}
+
+		// the lists passed to @@ decorators are field names
+		for _, dec := range model.Decorators {
+			if dec.Callable == nil {
+				continue
+			}
+
+			for _, arg := range dec.Callable.Arguments {
+				if arg.Value == nil || arg.Value.Type != ast.ValueTypeList {
+					continue
+				}
+
+				for _, value := range arg.Value.Items {
+					d.addReference(&reference{token: value.Token, kind: referenceField, model: modelName, name: value.Value})
+				}
+			}
+		}
+	}
+}
+
+func (d *Document) addReference(ref *reference) {
+	if ref.token != nil {
+		d.references = append(d.references, ref)
+	}
+}
+
+func (d *Document) referenceAt(pos Position) (*reference, bool) {
+	pos.Character = d.column(pos)
+
+	for _, ref := range d.references {
+		if ref.token.File == d.path && ref.contains(pos) {
+			return ref, true
+		}
+	}
+
+	return nil, false
+}
+
+func (d *Document) definitionOf(ref *reference) (*reference, bool) {
+	for _, other := range d.references {
+		if other.definition && other.matches(ref) {
+			return other, true
+		}
+	}
+
+	return nil, false
+}
+
+// Definition returns where the model, enum or field at the position is
+// declared.
+func (d *Document) Definition(pos Position) (*Location, bool) {
+	ref, ok := d.referenceAt(pos)
+	if !ok {
+		return nil, false
+	}
+
+	def, ok := d.definitionOf(ref)
+	if !ok {
+		return nil, false
+	}
+
+	location := d.location(def)
+	return &location, true
+}
+
+// Rename renames the model, enum or field at the position everywhere it is
+// used.
+func (d *Document) Rename(pos Position, newName string) (*WorkspaceEdit, error) {
+	token := lexer.NewLexer(newName).Next()
+	if token.Type != lexer.TokenTypeIdent || token.Literal != newName {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: newName + " is not a valid name"}
+	}
+
+	ref, ok := d.referenceAt(pos)
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "there is nothing to rename at the position"}
+	}
+
+	if _, ok := d.definitionOf(ref); !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: ref.name + " is not declared in the document"}
+	}
+
+	changes := map[string][]TextEdit{}
+	for _, other := range d.references {
+		if other.matches(ref) {
+			location := d.location(other)
+			changes[location.URI] = append(changes[location.URI], TextEdit{Range: location.Range, NewText: newName})
+		}
+	}
+
+	return &WorkspaceEdit{Changes: changes}, nil
+}
+
+// Format returns the edit replacing the document with its formatted text.
+// A document with syntax errors is not formatted, as the items the parser
+// skipped would be lost.
+func (d *Document) Format() ([]TextEdit, error) {
+	file, err := parser.ParseFile(d.path, []byte(d.Text))
+	if err != nil {
+		return nil, errors.New("unable to format a document with syntax errors")
+	}
+
+	formatted := file.String()
+	if formatted == d.Text {
+		return []TextEdit{}, nil
+	}
+
+	lines := strings.Split(d.Text, "\n")
+	last := len(lines) - 1
+	end := d.position(d.path, last, utf8.RuneCountInString(lines[last]))
+
+	return []TextEdit{{Range: Range{End: end}, NewText: formatted}}, nil
+}
+
+// modelAt returns the model whose block contains the line.
+func (d *Document) modelAt(line int) (*ast.Model, bool) {
+	var found *ast.Model
+	for _, model := range d.ast.Models {
+		if model.Token.File != d.path {
+			continue
+		}
+
+		if model.Token.Row <= line && (found == nil || model.Token.Row > found.Token.Row) {
+			found = model
+		}
+	}
+
+	return found, found != nil
+}
+
+func (d *Document) location(ref *reference) Location {
+	uri := d.URI
+	if ref.token.File != "" {
+		uri = fileURI(ref.token.File)
+	}
+
+	return Location{URI: uri, Range: d.tokenRange(ref.token)}
+}
+
+func (d *Document) tokenRange(token *lexer.Token) Range {
+	start := diagnostic.Start(token)
+	end := diagnostic.End(token)
+
+	return Range{
+		Start: d.position(token.File, start.Row, start.Col),
+		End:   d.position(token.File, end.Row, end.Col),
+	}
+}
+
+// position returns the protocol position of a row and rune column of the
+// document or of a file it imports. The protocol counts the characters of a
+// line in UTF-16 code units, the lexer counts runes.
+func (d *Document) position(file string, row int, col int) Position {
+	return Position{Line: row, Character: utf16Offset(d.line(file, row), col)}
+}
+
+// column returns the rune column of a protocol position in the document.
+func (d *Document) column(pos Position) int {
+	return runeColumn(d.line(d.path, pos.Line), pos.Character)
+}
+
+// line returns a line of the document or of a file it imports, it is empty
+// if the file can not be read.
+func (d *Document) line(file string, row int) string {
+	text := d.Text
+	if file != "" && file != d.path {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return ""
+		}
+		text = string(data)
+	}
+
+	lines := strings.Split(text, "\n")
+	if row < 0 || row >= len(lines) {
+		return ""
+	}
+
+	return lines[row]
+}
+
+// utf16Offset returns the UTF-16 offset of a rune column in the line,
+// columns past the end of the line count one unit each.
+func utf16Offset(line string, col int) int {
+	offset := 0
+	for _, ch := range line {
+		if col == 0 {
+			break
+		}
+
+		offset += utf16Len(ch)
+		col--
+	}
+
+	return offset + col
+}
+
+// runeColumn returns the rune column of a UTF-16 offset in the line, an
+// offset inside a surrogate pair is the column of its rune.
+func runeColumn(line string, offset int) int {
+	col := 0
+	for _, ch := range line {
+		if offset <= 0 {
+			return col
+		}
+
+		offset -= utf16Len(ch)
+		col++
+	}
+
+	return col + max(offset, 0)
+}
+
+func utf16Len(ch rune) int {
+	if ch >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+// filePath returns the path of a file:// uri and an empty string for other
+// uris.
+func filePath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+
+	return filepath.Clean(u.Path)
+}
+
+func fileURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
@@ -0,0 +1,105 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/generator"
+)
+
+// Hover describes the name at the position. A model or enum shows its
+// block, a field the column and the Go field the generators of the db
+// config block write for it.
+func (d *Document) Hover(pos Position) (*Hover, bool) {
+	ref, ok := d.referenceAt(pos)
+	if !ok {
+		return nil, false
+	}
+
+	var sb strings.Builder
+
+	switch ref.kind {
+	case referenceSymbol:
+		symbol, ok := d.symbols.Lookup(ref.name)
+		if !ok {
+			return nil, false
+		}
+
+		writeCode(&sb, "gophoria", symbolText(symbol))
+	case referenceField:
+		def, ok := d.definitionOf(ref)
+		if !ok {
+			return nil, false
+		}
+
+		d.writeField(&sb, def.field)
+	}
+
+	r := d.tokenRange(ref.token)
+	hover := Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: sb.String()},
+		Range:    &r,
+	}
+
+	return &hover, true
+}
+
+func (d *Document) writeField(sb *strings.Builder, field *ast.Declaration) {
+	writeCode(sb, "gophoria", field.String())
+
+	if !d.parsed {
+		return
+	}
+
+	provider, ok := d.ast.ConfigItem("db", "provider")
+	if ok {
+		column, ok := d.generateField(provider.Value.Value, field)
+		if ok {
+			writeCode(sb, "sql", column)
+		}
+	}
+
+	lib, ok := d.ast.ConfigItem("db", "lib")
+	if ok {
+		goField, ok := d.generateField(lib.Value.Value, field)
+		if ok {
+			writeCode(sb, "go", goField)
+		}
+	}
+}
+
+func (d *Document) generateField(name string, field *ast.Declaration) (string, bool) {
+	gen, err := generator.GetGenerator(name)
+	if err != nil {
+		return "", false
+	}
+
+	fieldGen, ok := gen.(generator.FieldGenerator)
+	if !ok {
+		return "", false
+	}
+
+	code, err := fieldGen.GenerateField(d.ast, field)
+	if err != nil || code == "" {
+		return "", false
+	}
+
+	return code, true
+}
+
+func symbolText(symbol *analyzer.Symbol) string {
+	if symbol.Kind == analyzer.SymbolKindModel {
+		return strings.TrimSuffix(symbol.Model.String(), "\n")
+	}
+
+	return strings.TrimSuffix(symbol.Enum.String(), "\n")
+}
+
+func writeCode(sb *strings.Builder, language string, code string) {
+	sb.WriteString("```")
+	sb.WriteString(language)
+	sb.WriteString("\n")
+	sb.WriteString(code)
+	sb.WriteString("\n```\n")
+}
@@ -0,0 +1,257 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+type handler func(params json.RawMessage) (any, error)
+
+// Server is a language server for gophoria files. The documents are synced
+// in full on every change.
+type Server struct {
+	conn      *Conn
+	documents map[string]*Document
+	handlers  map[string]handler
+	shutdown  bool
+}
+
+func NewServer(reader io.Reader, writer io.Writer) *Server {
+	s := Server{
+		conn:      NewConn(reader, writer),
+		documents: map[string]*Document{},
+	}
+
+	s.handlers = map[string]handler{
+		"initialize":                      s.initialize,
+		"shutdown":                        s.shutdownRequest,
+		"textDocument/didOpen":            s.didOpen,
+		"textDocument/didChange":          s.didChange,
+		"textDocument/didClose":           s.didClose,
+		"textDocument/definition":         s.definition,
+		"textDocument/hover":              s.hover,
+		"textDocument/completion":         s.completion,
+		"textDocument/rename":             s.rename,
+		"textDocument/formatting":         s.formatting,
+		"initialized":                     s.ignore,
+		"$/cancelRequest":                 s.ignore,
+		"workspace/didChangeWatchedFiles": s.ignore,
+	}
+
+	return &s
+}
+
+// Run handles messages until the client sends exit or closes the input.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.Read()
+		if err != nil {
+			var responseErr *ResponseError
+			if errors.As(err, &responseErr) {
+				s.conn.ReplyError(nil, responseErr)
+				continue
+			}
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit before shutdown")
+			}
+
+			return nil
+		}
+
+		err = s.handle(msg)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle calls the handler of the message and replies to requests.
+// Notifications without handler are ignored.
+func (s *Server) handle(msg *Message) error {
+	h, ok := s.handlers[msg.Method]
+	if !ok {
+		if msg.ID == nil {
+			return nil
+		}
+
+		return s.conn.ReplyError(msg.ID, &ResponseError{Code: CodeMethodNotFound, Message: "method " + msg.Method + " is not supported"})
+	}
+
+	result, err := h(msg.Params)
+	if msg.ID == nil {
+		return nil
+	}
+
+	if err != nil {
+		responseErr := &ResponseError{Code: CodeInternalError, Message: err.Error()}
+		errors.As(err, &responseErr)
+
+		return s.conn.ReplyError(msg.ID, responseErr)
+	}
+
+	return s.conn.Reply(msg.ID, result)
+}
+
+func (s *Server) ignore(_ json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(_ json.RawMessage) (any, error) {
+	result := InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           TextDocumentSyncFull,
+			DefinitionProvider:         true,
+			HoverProvider:              true,
+			CompletionProvider:         CompletionOptions{TriggerCharacters: []string{"@", "(", ",", ":", "["}},
+			RenameProvider:             true,
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "gophoria"},
+	}
+
+	return result, nil
+}
+
+func (s *Server) shutdownRequest(_ json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(raw json.RawMessage) (any, error) {
+	params := DidOpenTextDocumentParams{}
+	err := unmarshal(raw, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+}
+
+func (s *Server) didChange(raw json.RawMessage) (any, error) {
+	params := DidChangeTextDocumentParams{}
+	err := unmarshal(raw, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(params.ContentChanges) == 0 {
+		return nil, nil
+	}
+
+	return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(raw json.RawMessage) (any, error) {
+	params := DidCloseTextDocumentParams{}
+	err := unmarshal(raw, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(s.documents, params.TextDocument.URI)
+
+	return nil, s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+// update replaces the text of the document and publishes its diagnostics.
+func (s *Server) update(uri string, text string) error {
+	doc := NewDocument(uri, text)
+	s.documents[uri] = doc
+
+	return s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: doc.Diagnostics()})
+}
+
+func (s *Server) definition(raw json.RawMessage) (any, error) {
+	params := TextDocumentPositionParams{}
+	doc, err := s.document(raw, &params, &params.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	location, ok := doc.Definition(params.Position)
+	if !ok {
+		return nil, nil
+	}
+
+	return location, nil
+}
+
+func (s *Server) hover(raw json.RawMessage) (any, error) {
+	params := TextDocumentPositionParams{}
+	doc, err := s.document(raw, &params, &params.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	hover, ok := doc.Hover(params.Position)
+	if !ok {
+		return nil, nil
+	}
+
+	return hover, nil
+}
+
+func (s *Server) completion(raw json.RawMessage) (any, error) {
+	params := TextDocumentPositionParams{}
+	doc, err := s.document(raw, &params, &params.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Complete(params.Position), nil
+}
+
+func (s *Server) rename(raw json.RawMessage) (any, error) {
+	params := RenameParams{}
+	doc, err := s.document(raw, &params, &params.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Rename(params.Position, params.NewName)
+}
+
+func (s *Server) formatting(raw json.RawMessage) (any, error) {
+	params := DocumentFormattingParams{}
+	doc, err := s.document(raw, &params, &params.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Format()
+}
+
+// document decodes the params of a request and returns the document they
+// refer to.
+func (s *Server) document(raw json.RawMessage, params any, id *TextDocumentIdentifier) (*Document, error) {
+	err := unmarshal(raw, params)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := s.documents[id.URI]
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "document " + id.URI + " is not open"}
+	}
+
+	return doc, nil
+}
+
+func unmarshal(raw json.RawMessage, params any) error {
+	err := json.Unmarshal(raw, params)
+	if err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package lsp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/lsp"
+)
+
+const schema = `db {
+  provider = "sqlite3"
+  lib = "sqlx"
+}
+
+model User {
+  id    int    @id @default(autoincrement())
+  posts Post[]
+}
+
+model Post {
+  id       int  @id
+  author   User @relation(field: authorId, reference: id)
+  authorId int
+
+  @@unique([id, authorId])
+}
+`
+
+// session runs the server on the given requests, each a method and its
+// params, and returns every message it wrote. Methods starting with
+// textDocument/did are sent as notifications.
+func session(t *testing.T, requests ...any) []*lsp.Message {
+	var input bytes.Buffer
+	for i := 0; i < len(requests); i += 2 {
+		params, err := json.Marshal(requests[i+1])
+		if err != nil {
+			t.Fatalf("unable to encode params: %s", err.Error())
+		}
+
+		msg := lsp.Message{JSONRPC: "2.0", Method: requests[i].(string), Params: params}
+		if !strings.HasPrefix(msg.Method, "textDocument/did") {
+			id := json.RawMessage(fmt.Sprintf("%d", i/2))
+			msg.ID = &id
+		}
+
+		body, _ := json.Marshal(msg)
+		fmt.Fprintf(&input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var output bytes.Buffer
+	err := lsp.NewServer(&input, &output).Run()
+	if err != nil {
+		t.Fatalf("unable to run server: %s", err.Error())
+	}
+
+	conn := lsp.NewConn(&output, nil)
+	messages := []*lsp.Message{}
+	for {
+		msg, err := conn.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unable to read message: %s", err.Error())
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages
+}
+
+func open(text string) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": "file:///project.gophoria", "languageId": "gophoria", "version": 1, "text": text}}
+}
+
+func at(line int, character int) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": "file:///project.gophoria"}, "position": map[string]any{"line": line, "character": character}}
+}
+
+func result(t *testing.T, msg *lsp.Message, v any) {
+	if msg.Error != nil {
+		t.Fatalf("expected result, got error %s", msg.Error.Message)
+	}
+
+	err := json.Unmarshal(msg.Result, v)
+	if err != nil {
+		t.Fatalf("unable to decode result: %s", err.Error())
+	}
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	messages := session(t, "textDocument/didOpen", open("model User {\n  id int @id\n  post Post\n}\n"))
+
+	if len(messages) != 1 || messages[0].Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected diagnostics to be published, got %d messages", len(messages))
+	}
+
+	params := lsp.PublishDiagnosticsParams{}
+	json.Unmarshal(messages[0].Params, &params)
+
+	if len(params.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(params.Diagnostics))
+	}
+
+	diag := params.Diagnostics[0]
+	expected := lsp.Range{Start: lsp.Position{Line: 2, Character: 7}, End: lsp.Position{Line: 2, Character: 11}}
+	if diag.Range != expected || diag.Severity != lsp.SeverityError || !strings.HasPrefix(diag.Message, "unknown type Post") {
+		t.Fatalf("unexpected diagnostic %+v", diag)
+	}
+}
+
+func TestServerNavigation(t *testing.T) {
+	messages := session(t,
+		"textDocument/didOpen", open(schema),
+		"textDocument/definition", at(12, 12),
+		"textDocument/definition", at(12, 35),
+		"textDocument/hover", at(6, 3),
+		"textDocument/rename", map[string]any{"textDocument": map[string]any{"uri": "file:///project.gophoria"}, "position": map[string]any{"line": 13, "character": 3}, "newName": "userId"},
+	)
+
+	location := lsp.Location{}
+	result(t, messages[1], &location)
+	if location.Range.Start != (lsp.Position{Line: 5, Character: 6}) {
+		t.Fatalf("expected definition of User, got %+v", location)
+	}
+
+	result(t, messages[2], &location)
+	if location.Range.Start != (lsp.Position{Line: 13, Character: 2}) {
+		t.Fatalf("expected definition of authorId, got %+v", location)
+	}
+
+	hover := lsp.Hover{}
+	result(t, messages[3], &hover)
+	expected := "```gophoria\nid int @id @default(autoincrement())\n```\n" +
+		"```sql\n\"id\" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT\n```\n" +
+		"```go\nId int `db:\"id\"`\n```\n"
+	if hover.Contents.Value != expected {
+		t.Fatalf("expected hover:\n%s\ngot:\n%s", expected, hover.Contents.Value)
+	}
+
+	edit := lsp.WorkspaceEdit{}
+	result(t, messages[4], &edit)
+	edits := edit.Changes["file:///project.gophoria"]
+	if len(edits) != 3 {
+		t.Fatalf("expected authorId to be renamed in 3 places, got %+v", edits)
+	}
+	for _, e := range edits {
+		if e.NewText != "userId" {
+			t.Fatalf("unexpected edit %+v", e)
+		}
+	}
+}
+
+func TestServerCompletion(t *testing.T) {
+	text := "model User {\n  id int @id\n  name \n  age int @\n  posts Post[] @relation(\n}\n\nmodel Post {\n  id int @id @default(\n}\n"
+
+	messages := session(t,
+		"textDocument/didOpen", open(text),
+		"textDocument/completion", at(2, 7),
+		"textDocument/completion", at(3, 11),
+		"textDocument/completion", at(4, 26),
+		"textDocument/completion", at(8, 22),
+	)
+
+	tests := []struct {
+		message  *lsp.Message
+		expected []string
+	}{
+		{messages[1], []string{"int", "real", "bool", "string", "DateTime", "User", "Post"}},
+		{messages[2], []string{"default", "id", "map", "nullable", "relation", "renamedFrom", "unique"}},
+		{messages[3], []string{"field", "reference", "onDelete", "onUpdate", "name", "id", "name"}},
+		{messages[4], []string{"value", "now", "uuid", "autoincrement", "ulid"}},
+	}
+
+	for _, test := range tests {
+		items := []lsp.CompletionItem{}
+		result(t, test.message, &items)
+
+		labels := []string{}
+		for _, item := range items {
+			labels = append(labels, item.Label)
+		}
+
+		if strings.Join(labels, " ") != strings.Join(test.expected, " ") {
+			t.Fatalf("expected completion %v, got %v", test.expected, labels)
+		}
+	}
+}
+
+func TestServerFormatting(t *testing.T) {
+	messages := session(t,
+		"textDocument/didOpen", open("model User {\n  id int @id\n  name string // full name\n}"),
+		"textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": "file:///project.gophoria"}},
+	)
+
+	edits := []lsp.TextEdit{}
+	result(t, messages[1], &edits)
+
+	expected := "model User {\n  id   int @id\n  name string // full name\n}\n"
+	if len(edits) != 1 || edits[0].NewText != expected || edits[0].Range.End != (lsp.Position{Line: 3, Character: 1}) {
+		t.Fatalf("unexpected edits %+v", edits)
+	}
+}
+
+func TestServerUnicode(t *testing.T) {
+	text := "model User {\n  id    int    @id\n  posts Post[]\n}\n\nmodel Post {\n  id       int  @id\n  author   User @relation(name: \"😀\", field: authorId, reference: id) @unknown\n  authorId int\n}\n"
+
+	messages := session(t,
+		"textDocument/didOpen", open(text),
+		"textDocument/definition", at(7, 53),
+		"textDocument/completion", at(7, 36),
+	)
+
+	params := lsp.PublishDiagnosticsParams{}
+	json.Unmarshal(messages[0].Params, &params)
+
+	// the emoji is one rune but two UTF-16 code units
+	expected := lsp.Range{Start: lsp.Position{Line: 7, Character: 71}, End: lsp.Position{Line: 7, Character: 78}}
+	if len(params.Diagnostics) != 1 || params.Diagnostics[0].Range != expected {
+		t.Fatalf("expected a diagnostic at %+v, got %+v", expected, params.Diagnostics)
+	}
+
+	location := lsp.Location{}
+	result(t, messages[1], &location)
+	if location.Range.Start != (lsp.Position{Line: 8, Character: 2}) {
+		t.Fatalf("expected definition of authorId, got %+v", location)
+	}
+
+	items := []lsp.CompletionItem{}
+	result(t, messages[2], &items)
+	if len(items) == 0 || items[0].Label != "field" {
+		t.Fatalf("expected the arguments of @relation, got %+v", items)
+	}
+}
@@ -0,0 +1,489 @@
+package migration
+
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+)
+
+// Diff computes the changes turning the schema from into the schema to. A
+// nil from is an empty schema, so the migration creates every table.
+//
+// Tables and columns are matched by their names in the database. A model
+// whose table is mapped to another name with @@map renames its table, and a
+// column which disappears while a column with the same definition appears
+// in the same table is taken as renamed.
+func Diff(from *ast.Ast, to *ast.Ast) *Migration {
+	if from == nil {
+		from = ast.NewAst()
+	}
+
+	m := Migration{
+		From: from,
+		To:   to,
+	}
+
+	m.Changes = append(m.Changes, diffEnums(from, to)...)
+
+	fromTables := joinTablesByName(from)
+	toTables := joinTablesByName(to)
+
+	for _, table := range analyzer.JoinTables(from) {
+		if next, ok := toTables[table.Name]; !ok || joinTableSignature(from, table) != joinTableSignature(to, next) {
+			m.Changes = append(m.Changes, &Change{Type: ChangeDropJoinTable, From: from, To: to, PrevJoinTable: table})
+		}
+	}
+
+	pairs := pairModels(from, to)
+	paired := map[*ast.Model]struct{}{}
+	for _, prev := range pairs {
+		paired[prev] = struct{}{}
+	}
+
+	for _, model := range SortModels(to.Models) {
+		prev, ok := pairs[model]
+		if ok && decorator.TableName(from, prev) != decorator.TableName(to, model) {
+			m.Changes = append(m.Changes, &Change{Type: ChangeRenameTable, From: from, To: to, Model: model, PrevModel: prev})
+		}
+	}
+
+	for _, model := range SortModels(to.Models) {
+		if _, ok := pairs[model]; !ok {
+			m.Changes = append(m.Changes, &Change{Type: ChangeCreateTable, From: from, To: to, Model: model})
+		}
+	}
+
+	for _, model := range SortModels(to.Models) {
+		prev, ok := pairs[model]
+		if !ok {
+			continue
+		}
+
+		change := diffModel(from, to, prev, model)
+		if change != nil {
+			m.Changes = append(m.Changes, change)
+		}
+	}
+
+	dropped := SortModels(from.Models)
+	for i := len(dropped) - 1; i >= 0; i-- {
+		if _, ok := paired[dropped[i]]; !ok {
+			m.Changes = append(m.Changes, &Change{Type: ChangeDropTable, From: from, To: to, PrevModel: dropped[i]})
+		}
+	}
+
+	for _, table := range analyzer.JoinTables(to) {
+		if prev, ok := fromTables[table.Name]; !ok || joinTableSignature(from, prev) != joinTableSignature(to, table) {
+			m.Changes = append(m.Changes, &Change{Type: ChangeCreateJoinTable, From: from, To: to, JoinTable: table})
+		}
+	}
+
+	return &m
+}
+
+func diffEnums(from *ast.Ast, to *ast.Ast) []*Change {
+	changes := []*Change{}
+	fromEnums := enumsByName(from)
+
+	for _, enum := range to.Enums {
+		prev, ok := fromEnums[enum.Name.Identifier]
+		if ok && enumSignature(prev) != enumSignature(enum) {
+			changes = append(changes, &Change{Type: ChangeAlterEnum, From: from, To: to, Enum: enum, PrevEnum: prev})
+		}
+	}
+
+	return changes
+}
+
+// pairModels returns the model of the schema from every model of the schema
+// to comes from, the model with the same table or else the model with the
+// same name if its table is not used anymore.
+func pairModels(from *ast.Ast, to *ast.Ast) map[*ast.Model]*ast.Model {
+	fromTables := map[string]*ast.Model{}
+	for _, model := range from.Models {
+		fromTables[decorator.TableName(from, model)] = model
+	}
+
+	toTables := map[string]struct{}{}
+	for _, model := range to.Models {
+		toTables[decorator.TableName(to, model)] = struct{}{}
+	}
+
+	pairs := map[*ast.Model]*ast.Model{}
+	for _, model := range to.Models {
+		if prev, ok := fromTables[decorator.TableName(to, model)]; ok {
+			pairs[model] = prev
+		}
+	}
+
+	fromModels := modelsByName(from)
+	for _, model := range to.Models {
+		if _, ok := pairs[model]; ok {
+			continue
+		}
+
+		prev, ok := fromModels[model.Name.Identifier]
+		if !ok {
+			continue
+		}
+		if _, ok := toTables[decorator.TableName(from, prev)]; !ok {
+			pairs[model] = prev
+		}
+	}
+
+	return pairs
+}
+
+// diffModel compares the columns and constraints of a model, it returns nil
+// if the table is unchanged.
+func diffModel(from *ast.Ast, to *ast.Ast, prev *ast.Model, model *ast.Model) *Change {
+	change := Change{
+		Type:      ChangeAlterTable,
+		From:      from,
+		To:        to,
+		Model:     model,
+		PrevModel: prev,
+		Table:     decorator.TableName(to, model),
+	}
+
+	prevColumns := columnsByName(from, prev)
+	columns := columnsByName(to, model)
+
+	added := []*ast.Declaration{}
+	for _, field := range columnsOf(to, model) {
+		prevField, ok := prevColumns[decorator.ColumnName(to, field)]
+		if !ok {
+			added = append(added, field)
+			continue
+		}
+
+		if columnSignature(from, prevField) != columnSignature(to, field) {
+			change.Columns = append(change.Columns, &ColumnChange{Type: ColumnAlter, Field: field, PrevField: prevField})
+		}
+	}
+
+	dropped := []*ast.Declaration{}
+	for _, field := range columnsOf(from, prev) {
+		if _, ok := columns[decorator.ColumnName(from, field)]; !ok {
+			dropped = append(dropped, field)
+		}
+	}
+
+	renames := []*ColumnChange{}
+	renamed := map[*ast.Declaration]struct{}{}
+	for _, field := range dropped {
+		next, ok := renameOf(from, to, field, added)
+		if ok {
+			renames = append(renames, &ColumnChange{Type: ColumnRename, Field: next, PrevField: field})
+			renamed[field] = struct{}{}
+			renamed[next] = struct{}{}
+		}
+	}
+	change.Columns = append(renames, change.Columns...)
+
+	for _, field := range dropped {
+		if _, ok := renamed[field]; !ok {
+			change.Columns = append(change.Columns, &ColumnChange{Type: ColumnDrop, PrevField: field})
+		}
+	}
+	for _, field := range added {
+		if _, ok := renamed[field]; !ok {
+			change.Columns = append(change.Columns, &ColumnChange{Type: ColumnAdd, Field: field})
+		}
+	}
+
+	prevConstraints := Constraints(from, prev)
+	constraints := Constraints(to, model)
+
+	for _, constraint := range prevConstraints {
+		if !hasConstraint(constraints, constraint) {
+			constraint.Type = ConstraintDrop
+			change.Constraints = append(change.Constraints, constraint)
+		}
+	}
+
+	for _, constraint := range constraints {
+		if !hasConstraint(prevConstraints, constraint) {
+			change.Constraints = append(change.Constraints, constraint)
+		}
+	}
+
+	if len(change.Columns) == 0 && len(change.Constraints) == 0 {
+		return nil
+	}
+
+	return &change
+}
+
+// renameOf returns the added column a dropped column was renamed to, which
+// is the column of the same field if only its column name changed, or of the
+// field naming it with @renamedFrom. Columns of other fields are dropped and
+// added even with the same definition, their data is not related.
+func renameOf(from *ast.Ast, to *ast.Ast, field *ast.Declaration, added []*ast.Declaration) (*ast.Declaration, bool) {
+	signature := columnSignature(from, field)
+
+	for _, other := range added {
+		name := other.Identifier.Identifier
+		if renamed := decorator.ForField(other).RenamedFrom; renamed != nil {
+			name = renamed.Name
+		}
+
+		if name == field.Identifier.Identifier && columnSignature(to, other) == signature {
+			return other, true
+		}
+	}
+
+	return nil, false
+}
+
+// columnsOf returns the fields of a model stored in a column, i.e. every
+// field which is not a relation.
+func columnsOf(schema *ast.Ast, model *ast.Model) []*ast.Declaration {
+	models := modelsByName(schema)
+	columns := []*ast.Declaration{}
+
+	for _, item := range model.Items {
+		if _, ok := models[item.DeclarationType.Name]; ok && item.DeclarationType.Type == ast.VariableTypeObject {
+			continue
+		}
+
+		columns = append(columns, item)
+	}
+
+	return columns
+}
+
+func columnsByName(schema *ast.Ast, model *ast.Model) map[string]*ast.Declaration {
+	columns := map[string]*ast.Declaration{}
+	for _, field := range columnsOf(schema, model) {
+		columns[decorator.ColumnName(schema, field)] = field
+	}
+
+	return columns
+}
+
+// Constraints returns the composite primary key, the foreign keys, the
+// composite unique constraints and the indexes of the model as changes
+// adding them, with the names of their columns.
+func Constraints(schema *ast.Ast, model *ast.Model) []*ConstraintChange {
+	constraints := []*ConstraintChange{}
+	table := decorator.TableName(schema, model)
+	decorators := decorator.ForModel(model)
+
+	if decorators.Id != nil {
+		constraints = append(constraints, &ConstraintChange{
+			Type:    ConstraintAdd,
+			Kind:    ConstraintPrimaryKey,
+			Name:    PrimaryKeyName(table),
+			Columns: ColumnNames(schema, model, decorators.Id.Fields),
+		})
+	}
+
+	models := modelsByName(schema)
+	for _, item := range model.Items {
+		relation := decorator.ForField(item).Relation
+		if relation == nil || item.DeclarationType.IsArray || relation.Field == "" {
+			continue
+		}
+
+		column := ColumnNames(schema, model, []string{relation.Field})[0]
+		constraint := ConstraintChange{
+			Type:            ConstraintAdd,
+			Kind:            ConstraintForeignKey,
+			Name:            ForeignKeyName(table, column),
+			Field:           item,
+			Relation:        relation,
+			Columns:         []string{column},
+			ReferenceTable:  item.DeclarationType.Name,
+			ReferenceColumn: relation.Reference,
+		}
+
+		if referenced, ok := models[item.DeclarationType.Name]; ok {
+			constraint.ReferenceTable = decorator.TableName(schema, referenced)
+			constraint.ReferenceColumn = ColumnNames(schema, referenced, []string{relation.Reference})[0]
+		}
+
+		constraints = append(constraints, &constraint)
+	}
+
+	for _, unique := range decorators.Unique {
+		columns := ColumnNames(schema, model, unique.Fields)
+		constraints = append(constraints, &ConstraintChange{
+			Type:    ConstraintAdd,
+			Kind:    ConstraintUnique,
+			Name:    UniqueName(table, columns),
+			Columns: columns,
+		})
+	}
+
+	for _, index := range decorators.Index {
+		columns := ColumnNames(schema, model, index.Fields)
+		name := index.Name
+		if name == "" {
+			name = IndexName(table, columns)
+		}
+
+		constraints = append(constraints, &ConstraintChange{
+			Type:    ConstraintAdd,
+			Kind:    ConstraintIndex,
+			Name:    name,
+			Columns: columns,
+		})
+	}
+
+	return constraints
+}
+
+// ColumnNames returns the columns of the named fields of the model, a name
+// which is not a field is kept.
+func ColumnNames(schema *ast.Ast, model *ast.Model, fields []string) []string {
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		columns[i] = name
+		for _, item := range model.Items {
+			if item.Identifier.Identifier == name {
+				columns[i] = decorator.ColumnName(schema, item)
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+func hasConstraint(constraints []*ConstraintChange, constraint *ConstraintChange) bool {
+	for _, other := range constraints {
+		if constraintSignature(other) == constraintSignature(constraint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func constraintSignature(constraint *ConstraintChange) string {
+	signature := constraint.Name + " " + strings.Join(constraint.Columns, ",")
+	if constraint.Relation != nil {
+		relation := constraint.Relation
+		signature += " " + constraint.ReferenceTable + "(" + constraint.ReferenceColumn + ") " + relation.OnDelete + " " + relation.OnUpdate
+	}
+
+	return signature
+}
+
+// columnSignature describes the definition of a column without its name,
+// including the values of its enum.
+func columnSignature(schema *ast.Ast, field *ast.Declaration) string {
+	var sb strings.Builder
+
+	sb.WriteString(field.DeclarationType.String())
+	for _, dec := range field.Decorators {
+		// the name of the column is compared on its own
+		if dec.Name.Identifier == "map" || dec.Name.Identifier == "renamedFrom" {
+			continue
+		}
+
+		sb.WriteString(" ")
+		sb.WriteString(dec.String())
+	}
+
+	if enum, ok := enumsByName(schema)[field.DeclarationType.Name]; ok && field.DeclarationType.Type == ast.VariableTypeObject {
+		sb.WriteString(" ")
+		sb.WriteString(enumSignature(enum))
+	}
+
+	return sb.String()
+}
+
+func enumSignature(enum *ast.Enum) string {
+	values := make([]string, len(enum.Items))
+	for i, item := range enum.Items {
+		values[i] = item.Value.String()
+	}
+
+	return "{" + strings.Join(values, ", ") + "}"
+}
+
+func joinTableSignature(schema *ast.Ast, table *analyzer.JoinTable) string {
+	signature := table.Name
+	for _, end := range []*analyzer.RelationEnd{table.A, table.B} {
+		signature += " " + end.Model.Name.Identifier + "." + end.Column
+		for _, item := range end.Model.Items {
+			if decorator.ForField(item).Id != nil {
+				signature += " " + columnSignature(schema, item)
+			}
+		}
+	}
+
+	return signature
+}
+
+func modelsByName(schema *ast.Ast) map[string]*ast.Model {
+	models := map[string]*ast.Model{}
+	for _, model := range schema.Models {
+		models[model.Name.Identifier] = model
+	}
+
+	return models
+}
+
+func enumsByName(schema *ast.Ast) map[string]*ast.Enum {
+	enums := map[string]*ast.Enum{}
+	for _, enum := range schema.Enums {
+		enums[enum.Name.Identifier] = enum
+	}
+
+	return enums
+}
+
+func joinTablesByName(schema *ast.Ast) map[string]*analyzer.JoinTable {
+	tables := map[string]*analyzer.JoinTable{}
+	for _, table := range analyzer.JoinTables(schema) {
+		tables[table.Name] = table
+	}
+
+	return tables
+}
+
+// SortModels orders models so that every model comes after the models it
+// references with @relation, i.e. referenced tables are created first.
+// A reference closing a cycle can not be satisfied and is ignored.
+func SortModels(models []*ast.Model) []*ast.Model {
+	byName := map[string]*ast.Model{}
+	for _, model := range models {
+		byName[model.Name.Identifier] = model
+	}
+
+	sorted := []*ast.Model{}
+	visited := map[*ast.Model]struct{}{}
+
+	var visit func(model *ast.Model)
+	visit = func(model *ast.Model) {
+		if _, ok := visited[model]; ok {
+			return
+		}
+		visited[model] = struct{}{}
+
+		for _, item := range model.Items {
+			relation := decorator.ForField(item).Relation
+			if relation == nil || item.DeclarationType.IsArray {
+				continue
+			}
+
+			referenced, ok := byName[item.DeclarationType.Name]
+			if ok {
+				visit(referenced)
+			}
+		}
+
+		sorted = append(sorted, model)
+	}
+
+	for _, model := range models {
+		visit(model)
+	}
+
+	return sorted
+}
@@ -0,0 +1,201 @@
+package migration
+
+import (
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/analyzer"
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/decorator"
+)
+
+type ChangeType int
+
+const (
+	ChangeCreateTable ChangeType = iota
+	ChangeDropTable
+	ChangeAlterTable
+	ChangeCreateJoinTable
+	ChangeDropJoinTable
+	ChangeAlterEnum
+	ChangeRenameTable
+)
+
+// Change is a single step of a migration. Nodes named Prev belong to the
+// schema the change migrates from, the others to the schema it migrates to.
+type Change struct {
+	Type ChangeType
+	From *ast.Ast
+	To   *ast.Ast
+
+	Model     *ast.Model
+	PrevModel *ast.Model
+
+	JoinTable     *analyzer.JoinTable
+	PrevJoinTable *analyzer.JoinTable
+
+	Enum     *ast.Enum
+	PrevEnum *ast.Enum
+
+	// Table is the name an altered table has while the change is applied, a
+	// renamed table is altered after the rename and reverted before it.
+	Table string
+	// Columns and Constraints are the changes of an altered table.
+	Columns     []*ColumnChange
+	Constraints []*ConstraintChange
+}
+
+type ColumnChangeType int
+
+const (
+	ColumnAdd ColumnChangeType = iota
+	ColumnDrop
+	ColumnRename
+	ColumnAlter
+)
+
+// ColumnChange is a change of a single column. An altered column may render
+// the same in a dialect, e.g. if only the values of its enum changed.
+type ColumnChange struct {
+	Type      ColumnChangeType
+	Field     *ast.Declaration
+	PrevField *ast.Declaration
+}
+
+type ConstraintChangeType int
+
+const (
+	ConstraintAdd ConstraintChangeType = iota
+	ConstraintDrop
+)
+
+type ConstraintKind int
+
+const (
+	ConstraintForeignKey ConstraintKind = iota
+	ConstraintUnique
+	ConstraintPrimaryKey
+	ConstraintIndex
+)
+
+// ConstraintChange adds or drops a table constraint or an index. A foreign
+// key has the relation field and the referenced column set, the others only
+// their columns.
+type ConstraintChange struct {
+	Type     ConstraintChangeType
+	Kind     ConstraintKind
+	Name     string
+	Field    *ast.Declaration
+	Relation *decorator.Relation
+	Columns  []string
+	// ReferenceTable and ReferenceColumn are the column a foreign key
+	// references.
+	ReferenceTable  string
+	ReferenceColumn string
+}
+
+// Migration holds the changes turning one schema into another.
+type Migration struct {
+	From    *ast.Ast
+	To      *ast.Ast
+	Changes []*Change
+}
+
+func (m *Migration) IsEmpty() bool {
+	return len(m.Changes) == 0
+}
+
+// Down returns the changes reverting the migration.
+func (m *Migration) Down() []*Change {
+	changes := make([]*Change, len(m.Changes))
+	for i, change := range m.Changes {
+		changes[len(m.Changes)-1-i] = change.Invert()
+	}
+
+	return changes
+}
+
+var invertedChanges = map[ChangeType]ChangeType{
+	ChangeCreateTable:     ChangeDropTable,
+	ChangeDropTable:       ChangeCreateTable,
+	ChangeAlterTable:      ChangeAlterTable,
+	ChangeCreateJoinTable: ChangeDropJoinTable,
+	ChangeDropJoinTable:   ChangeCreateJoinTable,
+	ChangeAlterEnum:       ChangeAlterEnum,
+	ChangeRenameTable:     ChangeRenameTable,
+}
+
+// Invert returns the change undoing this change.
+func (c *Change) Invert() *Change {
+	inverted := Change{
+		Type:          invertedChanges[c.Type],
+		From:          c.To,
+		To:            c.From,
+		Model:         c.PrevModel,
+		PrevModel:     c.Model,
+		JoinTable:     c.PrevJoinTable,
+		PrevJoinTable: c.JoinTable,
+		Enum:          c.PrevEnum,
+		PrevEnum:      c.Enum,
+		Table:         c.Table,
+	}
+
+	for i := len(c.Columns) - 1; i >= 0; i-- {
+		inverted.Columns = append(inverted.Columns, c.Columns[i].Invert())
+	}
+
+	for i := len(c.Constraints) - 1; i >= 0; i-- {
+		inverted.Constraints = append(inverted.Constraints, c.Constraints[i].Invert())
+	}
+
+	return &inverted
+}
+
+var invertedColumnChanges = map[ColumnChangeType]ColumnChangeType{
+	ColumnAdd:    ColumnDrop,
+	ColumnDrop:   ColumnAdd,
+	ColumnRename: ColumnRename,
+	ColumnAlter:  ColumnAlter,
+}
+
+func (c *ColumnChange) Invert() *ColumnChange {
+	inverted := ColumnChange{
+		Type:      invertedColumnChanges[c.Type],
+		Field:     c.PrevField,
+		PrevField: c.Field,
+	}
+
+	return &inverted
+}
+
+func (c *ConstraintChange) Invert() *ConstraintChange {
+	inverted := *c
+	if c.Type == ConstraintAdd {
+		inverted.Type = ConstraintDrop
+	} else {
+		inverted.Type = ConstraintAdd
+	}
+
+	return &inverted
+}
+
+// ForeignKeyName is the name of the foreign key constraint on a column, the
+// name postgres gives an unnamed constraint.
+func ForeignKeyName(table string, column string) string {
+	return table + "_" + column + "_fkey"
+}
+
+// UniqueName is the name of a unique constraint over the columns.
+func UniqueName(table string, columns []string) string {
+	return table + "_" + strings.Join(columns, "_") + "_key"
+}
+
+// PrimaryKeyName is the name of the primary key constraint of a table.
+func PrimaryKeyName(table string) string {
+	return table + "_pkey"
+}
+
+// IndexName is the name of an index over the columns which is not named
+// with @@index(name: ...).
+func IndexName(table string, columns []string) string {
+	return table + "_" + strings.Join(columns, "_") + "_idx"
+}
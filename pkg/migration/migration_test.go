@@ -0,0 +1,320 @@
+package migration_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/migration"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Ast {
+	parser := parser.NewParser(lexer.NewLexer(input))
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	return ast
+}
+
+func TestDiff(t *testing.T) {
+	from := parse(t, `
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model User {
+  id      int     @id
+  name    string
+  role    Role
+}
+
+model Post {
+  id      int     @id
+  title   string
+  author  User    @relation(field: authorId, reference: id)
+  authorId int
+}
+
+model Tag {
+  id      int     @id
+}`)
+
+	to := parse(t, `
+enum Role {
+  admin = "admin"
+  user = "user"
+  guest = "guest"
+}
+
+model User {
+  id       int     @id
+  fullName string  @renamedFrom("name")
+  role     Role
+  age      int     @nullable
+}
+
+model Post {
+  id      int     @id
+  title   string  @nullable
+  author  User    @relation(field: authorId, reference: id, onDelete: Cascade)
+  authorId int
+
+  @@unique([title, authorId])
+}
+
+model Comment {
+  id      int     @id
+  post    Post    @relation(field: postId, reference: id)
+  postId  int
+}`)
+
+	m := migration.Diff(from, to)
+
+	expected := []migration.ChangeType{
+		migration.ChangeAlterEnum,
+		migration.ChangeCreateTable,
+		migration.ChangeAlterTable,
+		migration.ChangeAlterTable,
+		migration.ChangeDropTable,
+	}
+
+	if len(m.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(m.Changes))
+	}
+
+	for i, change := range m.Changes {
+		if change.Type != expected[i] {
+			t.Fatalf("change %d: expected type %d, got %d", i, expected[i], change.Type)
+		}
+	}
+
+	if m.Changes[1].Model.Name.Identifier != "Comment" {
+		t.Fatalf("expected Comment to be created, got %s", m.Changes[1].Model.Name.Identifier)
+	}
+
+	if m.Changes[4].PrevModel.Name.Identifier != "Tag" {
+		t.Fatalf("expected Tag to be dropped, got %s", m.Changes[4].PrevModel.Name.Identifier)
+	}
+
+	user := m.Changes[2]
+	if user.Model.Name.Identifier != "User" {
+		t.Fatalf("expected User to be altered, got %s", user.Model.Name.Identifier)
+	}
+
+	userColumns := []struct {
+		changeType migration.ColumnChangeType
+		name       string
+	}{
+		{migration.ColumnRename, "fullName"},
+		{migration.ColumnAlter, "role"},
+		{migration.ColumnAdd, "age"},
+	}
+
+	if len(user.Columns) != len(userColumns) {
+		t.Fatalf("expected %d column changes of User, got %d", len(userColumns), len(user.Columns))
+	}
+
+	for i, column := range user.Columns {
+		if column.Type != userColumns[i].changeType || column.Field.Identifier.Identifier != userColumns[i].name {
+			t.Fatalf("column change %d of User: expected %d %s, got %d %s", i, userColumns[i].changeType, userColumns[i].name, column.Type, column.Field.Identifier.Identifier)
+		}
+	}
+
+	post := m.Changes[3]
+	if len(post.Columns) != 1 || post.Columns[0].Type != migration.ColumnAlter {
+		t.Fatalf("expected title of Post to be altered")
+	}
+
+	constraints := []string{
+		"Post_authorId_fkey",
+		"Post_authorId_fkey",
+		"Post_title_authorId_key",
+	}
+	constraintTypes := []migration.ConstraintChangeType{
+		migration.ConstraintDrop,
+		migration.ConstraintAdd,
+		migration.ConstraintAdd,
+	}
+
+	if len(post.Constraints) != len(constraints) {
+		t.Fatalf("expected %d constraint changes of Post, got %d", len(constraints), len(post.Constraints))
+	}
+
+	for i, constraint := range post.Constraints {
+		if constraint.Name != constraints[i] || constraint.Type != constraintTypes[i] {
+			t.Fatalf("constraint change %d of Post: expected %d %s, got %d %s", i, constraintTypes[i], constraints[i], constraint.Type, constraint.Name)
+		}
+	}
+
+	down := m.Down()
+	if down[0].Type != migration.ChangeCreateTable || down[0].Model.Name.Identifier != "Tag" {
+		t.Fatalf("expected Tag to be created first when reverted")
+	}
+
+	if down[len(down)-1].Type != migration.ChangeAlterEnum || len(down[len(down)-1].Enum.Items) != 2 {
+		t.Fatalf("expected Role to be reverted last")
+	}
+}
+
+func TestDiffMapped(t *testing.T) {
+	from := parse(t, `
+model User {
+  id      int     @id
+  name    string
+}`)
+
+	to := parse(t, `
+model User {
+  id      int     @id
+  name    string  @map("full_name")
+
+  @@index([name])
+  @@map("users")
+}`)
+
+	m := migration.Diff(from, to)
+
+	if len(m.Changes) != 2 || m.Changes[0].Type != migration.ChangeRenameTable || m.Changes[1].Type != migration.ChangeAlterTable {
+		t.Fatalf("expected User to be renamed and altered, got %d changes", len(m.Changes))
+	}
+
+	user := m.Changes[1]
+	if user.Table != "users" {
+		t.Fatalf("expected users to be altered, got %s", user.Table)
+	}
+
+	if len(user.Columns) != 1 || user.Columns[0].Type != migration.ColumnRename {
+		t.Fatalf("expected name of User to be renamed")
+	}
+
+	if len(user.Constraints) != 1 || user.Constraints[0].Kind != migration.ConstraintIndex || user.Constraints[0].Name != "users_full_name_idx" {
+		t.Fatalf("expected index users_full_name_idx to be added")
+	}
+
+	down := m.Down()
+	if down[0].Type != migration.ChangeAlterTable || down[0].Table != "users" || down[1].Type != migration.ChangeRenameTable {
+		t.Fatalf("expected users to be reverted before it is renamed back")
+	}
+}
+
+func TestDiffUnrelatedColumns(t *testing.T) {
+	from := parse(t, `
+model User {
+  id      int     @id
+  bio     string  @nullable
+}`)
+
+	to := parse(t, `
+model User {
+  id       int     @id
+  nickname string  @nullable
+}`)
+
+	m := migration.Diff(from, to)
+
+	if len(m.Changes) != 1 || m.Changes[0].Type != migration.ChangeAlterTable {
+		t.Fatalf("expected User to be altered, got %d changes", len(m.Changes))
+	}
+
+	columns := m.Changes[0].Columns
+	if len(columns) != 2 {
+		t.Fatalf("expected 2 column changes of User, got %d", len(columns))
+	}
+
+	if columns[0].Type != migration.ColumnDrop || columns[0].PrevField.Identifier.Identifier != "bio" {
+		t.Fatalf("expected bio to be dropped")
+	}
+
+	if columns[1].Type != migration.ColumnAdd || columns[1].Field.Identifier.Identifier != "nickname" {
+		t.Fatalf("expected nickname to be added")
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	input := `
+model Post {
+  id      int     @id
+  tags    Tag[]
+}
+
+model Tag {
+  id      int     @id
+  posts   Post[]
+}`
+
+	m := migration.Diff(parse(t, input), parse(t, input))
+	if !m.IsEmpty() {
+		t.Fatalf("expected no changes, got %d", len(m.Changes))
+	}
+
+	m = migration.Diff(nil, parse(t, input))
+
+	expected := []migration.ChangeType{
+		migration.ChangeCreateTable,
+		migration.ChangeCreateTable,
+		migration.ChangeCreateJoinTable,
+	}
+
+	if len(m.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(m.Changes))
+	}
+
+	for i, change := range m.Changes {
+		if change.Type != expected[i] {
+			t.Fatalf("change %d: expected type %d, got %d", i, expected[i], change.Type)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	schema := parse(t, `
+enum Role {
+  admin = "admin"
+}
+
+model User {
+  id      string  @id @default(uuid())
+  role    Role    @default(admin)
+  posts   Post[]
+}
+
+model Post {
+  id      int     @id @default(autoincrement())
+  author  User    @relation(field: authorId, reference: id, onDelete: Cascade)
+  authorId string
+
+  @@unique([id, authorId])
+}`)
+
+	file := path.Join(t.TempDir(), migration.SnapshotFile)
+
+	empty, err := migration.LoadSnapshot(file)
+	if err != nil {
+		t.Fatalf("unable to load missing snapshot: %s", err.Error())
+	}
+
+	if len(empty.Models) != 0 {
+		t.Fatalf("expected a missing snapshot to be empty")
+	}
+
+	err = migration.SaveSnapshot(file, schema)
+	if err != nil {
+		t.Fatalf("unable to save snapshot: %s", err.Error())
+	}
+
+	loaded, err := migration.LoadSnapshot(file)
+	if err != nil {
+		t.Fatalf("unable to load snapshot: %s", err.Error())
+	}
+
+	m := migration.Diff(loaded, schema)
+	if !m.IsEmpty() {
+		t.Fatalf("expected a loaded snapshot to match its schema, got %d changes", len(m.Changes))
+	}
+}
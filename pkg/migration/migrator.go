@@ -0,0 +1,437 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationsTable records the migrations applied to a database.
+const MigrationsTable = "_gophoria_migrations"
+
+var providers = map[string]struct{}{
+	"sqlite3":  {},
+	"postgres": {},
+	"mysql":    {},
+}
+
+// File is a migration of the migrations directory, the statements of
+// <name>.up.sql and <name>.down.sql.
+type File struct {
+	Name string
+	Up   string
+	Down string
+	// Checksum is the sha256 of the up statements, an applied migration
+	// must not be edited.
+	Checksum string
+}
+
+// Status is the state of a migration in the database.
+type Status struct {
+	Name      string
+	Applied   bool
+	AppliedAt string
+	// Modified is set if the up file was edited after it was applied.
+	Modified bool
+	// Missing is set if the file of an applied migration was removed.
+	Missing bool
+}
+
+type record struct {
+	name      string
+	checksum  string
+	appliedAt string
+}
+
+// Migrator applies the migrations of a directory to a database, each file
+// in a transaction.
+type Migrator struct {
+	db       *sql.DB
+	provider string
+	dir      string
+}
+
+func NewMigrator(db *sql.DB, provider string, dir string) (*Migrator, error) {
+	if _, ok := providers[provider]; !ok {
+		return nil, fmt.Errorf("migrations are not supported for provider %s", provider)
+	}
+
+	m := Migrator{
+		db:       db,
+		provider: provider,
+		dir:      dir,
+	}
+
+	return &m, nil
+}
+
+// LoadFiles reads the migrations of a directory ordered by name. Names start
+// with a timestamp, so this is the order they were generated in.
+func LoadFiles(dir string) ([]*File, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*File{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".up.sql")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		up, err := os.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		down, err := os.ReadFile(path.Join(dir, name+".down.sql"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(up)
+
+		files = append(files, &File{
+			Name:     name,
+			Up:       string(up),
+			Down:     string(down),
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return files, nil
+}
+
+// Status lists the migration files and the applied migrations whose files
+// are missing.
+func (m *Migrator) Status() ([]*Status, error) {
+	files, records, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []*Status{}
+	for _, file := range files {
+		status := Status{Name: file.Name}
+
+		if rec, ok := records[file.Name]; ok {
+			status.Applied = true
+			status.AppliedAt = rec.appliedAt
+			status.Modified = rec.checksum != file.Checksum
+		}
+
+		statuses = append(statuses, &status)
+	}
+
+	for _, rec := range records {
+		if _, ok := findFile(files, rec.name); !ok {
+			statuses = append(statuses, &Status{Name: rec.name, Applied: true, AppliedAt: rec.appliedAt, Missing: true})
+		}
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations and returns their names.
+func (m *Migrator) Up() ([]string, error) {
+	files, records, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	err = verify(files, records)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if _, ok := records[file.Name]; ok {
+			continue
+		}
+
+		err = m.apply(file)
+		if err != nil {
+			return names, err
+		}
+
+		names = append(names, file.Name)
+	}
+
+	return names, nil
+}
+
+// Down reverts the last applied migration and returns its name, it returns
+// an empty name if no migration is applied.
+func (m *Migrator) Down() (string, error) {
+	files, records, err := m.load()
+	if err != nil {
+		return "", err
+	}
+
+	err = verify(files, records)
+	if err != nil {
+		return "", err
+	}
+
+	file := lastApplied(files, records)
+	if file == nil {
+		return "", nil
+	}
+
+	return file.Name, m.revert(file)
+}
+
+// Redo reverts the last applied migration and applies it again.
+func (m *Migrator) Redo() (string, error) {
+	name, err := m.Down()
+	if err != nil || name == "" {
+		return name, err
+	}
+
+	files, err := LoadFiles(m.dir)
+	if err != nil {
+		return name, err
+	}
+
+	for _, file := range files {
+		if file.Name == name {
+			return name, m.apply(file)
+		}
+	}
+
+	return name, fmt.Errorf("migration %s not found", name)
+}
+
+func (m *Migrator) load() ([]*File, map[string]*record, error) {
+	files, err := LoadFiles(m.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = m.db.Exec("CREATE TABLE IF NOT EXISTS " + MigrationsTable + " (name VARCHAR(255) NOT NULL PRIMARY KEY, checksum VARCHAR(64) NOT NULL, applied_at VARCHAR(64) NOT NULL)")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := m.db.Query("SELECT name, checksum, applied_at FROM " + MigrationsTable)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	records := map[string]*record{}
+	for rows.Next() {
+		rec := record{}
+
+		err = rows.Scan(&rec.name, &rec.checksum, &rec.appliedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		records[rec.name] = &rec
+	}
+
+	return files, records, rows.Err()
+}
+
+// verify refuses to migrate a database whose applied migrations were edited
+// or removed, the schema would not match the migrations any more.
+func verify(files []*File, records map[string]*record) error {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file, ok := findFile(files, name)
+		if !ok {
+			return fmt.Errorf("applied migration %s is missing", name)
+		}
+
+		if file.Checksum != records[name].checksum {
+			return fmt.Errorf("applied migration %s was modified", name)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(file *File) error {
+	return m.run(file.Name, file.Up, func(tx *sql.Tx) error {
+		_, err := tx.Exec(m.bind("INSERT INTO "+MigrationsTable+" (name, checksum, applied_at) VALUES (?, ?, ?)"), file.Name, file.Checksum, time.Now().UTC().Format(time.RFC3339))
+		return err
+	})
+}
+
+func (m *Migrator) revert(file *File) error {
+	return m.run(file.Name, file.Down, func(tx *sql.Tx) error {
+		_, err := tx.Exec(m.bind("DELETE FROM "+MigrationsTable+" WHERE name = ?"), file.Name)
+		return err
+	})
+}
+
+// run executes the statements of a migration and records it in a single
+// transaction.
+func (m *Migrator) run(name string, statements string, record func(tx *sql.Tx) error) error {
+	ctx := context.Background()
+
+	// the pragmas of sqlite hold for a connection, not for the pool
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.provider == "sqlite3" {
+		restore, err := disableForeignKeys(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(statements) != "" {
+		_, err = tx.Exec(statements)
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+
+	if m.provider == "sqlite3" {
+		err = checkForeignKeys(tx)
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// disableForeignKeys turns off foreign keys while sqlite rebuilds a table,
+// dropping the old table would otherwise delete the rows referencing it.
+func disableForeignKeys(ctx context.Context, conn *sql.Conn) (func(), error) {
+	enabled := 0
+
+	err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	if err != nil {
+		return nil, err
+	}
+
+	restore := func() {
+		conn.ExecContext(ctx, "PRAGMA foreign_keys = "+strconv.Itoa(enabled))
+	}
+
+	return restore, nil
+}
+
+// checkForeignKeys fails if a migration left rows referencing missing rows.
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		table := ""
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+
+		values := make([]any, len(columns))
+		values[0] = &table
+		for i := 1; i < len(values); i++ {
+			values[i] = new(any)
+		}
+
+		err = rows.Scan(values...)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("foreign key violation in table %s", table)
+	}
+
+	return rows.Err()
+}
+
+// bind replaces ? placeholders with the numbered placeholders of postgres.
+func (m *Migrator) bind(query string) string {
+	if m.provider != "postgres" {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+func lastApplied(files []*File, records map[string]*record) *File {
+	for i := len(files) - 1; i >= 0; i-- {
+		if _, ok := records[files[i].Name]; ok {
+			return files[i]
+		}
+	}
+
+	return nil
+}
+
+func findFile(files []*File, name string) (*File, bool) {
+	for _, file := range files {
+		if file.Name == name {
+			return file, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,201 @@
+package migration_test
+
+import (
+	"database/sql"
+	"os"
+	"path"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/gophoria/gophoria/pkg/migration"
+)
+
+func writeMigration(t *testing.T, dir string, name string, up string, down string) {
+	err := os.WriteFile(path.Join(dir, name+".up.sql"), []byte(up), 0644)
+	if err != nil {
+		t.Fatalf("unable to write migration: %s", err.Error())
+	}
+
+	err = os.WriteFile(path.Join(dir, name+".down.sql"), []byte(down), 0644)
+	if err != nil {
+		t.Fatalf("unable to write migration: %s", err.Error())
+	}
+}
+
+func tables(t *testing.T, db *sql.DB) []string {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatalf("unable to list tables: %s", err.Error())
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		name := ""
+		rows.Scan(&name)
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func expectTables(t *testing.T, db *sql.DB, expected ...string) {
+	actual := tables(t, db)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected tables %v, got %v", expected, actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected tables %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	workingDir := t.TempDir()
+	dir := path.Join(workingDir, "migrations")
+	os.MkdirAll(dir, 0755)
+
+	db, err := sql.Open("sqlite3", path.Join(workingDir, "test.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	writeMigration(t, dir, "20240101000000_init",
+		"CREATE TABLE User (id INTEGER PRIMARY KEY);\nCREATE TABLE Post (id INTEGER PRIMARY KEY, authorId INTEGER REFERENCES User(id) ON DELETE CASCADE);\n",
+		"DROP TABLE Post;\nDROP TABLE User;\n")
+	writeMigration(t, dir, "20240102000000_update",
+		"CREATE TABLE User_new (id INTEGER PRIMARY KEY, name TEXT);\nINSERT INTO User_new (id) SELECT id FROM User;\nDROP TABLE User;\nALTER TABLE User_new RENAME TO User;\n",
+		"")
+
+	migrator, err := migration.NewMigrator(db, "sqlite3", dir)
+	if err != nil {
+		t.Fatalf("unable to create migrator: %s", err.Error())
+	}
+
+	names, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("unable to migrate up: %s", err.Error())
+	}
+
+	if len(names) != 2 {
+		t.Fatalf("expected 2 applied migrations, got %d", len(names))
+	}
+
+	expectTables(t, db, "Post", "User", migration.MigrationsTable)
+
+	names, err = migrator.Up()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected no pending migrations, got %v %v", names, err)
+	}
+
+	_, err = db.Exec("INSERT INTO User (id) VALUES (1); INSERT INTO Post (id, authorId) VALUES (1, 1);")
+	if err != nil {
+		t.Fatalf("unable to insert rows: %s", err.Error())
+	}
+
+	name, err := migrator.Redo()
+	if err != nil || name != "20240102000000_update" {
+		t.Fatalf("unable to redo last migration: %s %v", name, err)
+	}
+
+	// rebuilding User must not cascade to its posts
+	posts := 0
+	db.QueryRow("SELECT count(*) FROM Post").Scan(&posts)
+	if posts != 1 {
+		t.Fatalf("expected post to be kept, got %d posts", posts)
+	}
+
+	name, err = migrator.Down()
+	if err != nil || name != "20240102000000_update" {
+		t.Fatalf("unable to revert last migration: %s %v", name, err)
+	}
+
+	name, err = migrator.Down()
+	if err != nil || name != "20240101000000_init" {
+		t.Fatalf("unable to revert first migration: %s %v", name, err)
+	}
+
+	expectTables(t, db, migration.MigrationsTable)
+
+	name, err = migrator.Down()
+	if err != nil || name != "" {
+		t.Fatalf("expected nothing to revert, got %s %v", name, err)
+	}
+}
+
+func TestMigratorRollback(t *testing.T) {
+	workingDir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", path.Join(workingDir, "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	writeMigration(t, workingDir, "1_broken", "CREATE TABLE User (id INTEGER PRIMARY KEY);\nCREATE TABLE User (id INTEGER PRIMARY KEY);\n", "")
+
+	migrator, _ := migration.NewMigrator(db, "sqlite3", workingDir)
+
+	_, err = migrator.Up()
+	if err == nil {
+		t.Fatalf("expected failing migration to fail")
+	}
+
+	expectTables(t, db, migration.MigrationsTable)
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unable to read status: %s", err.Error())
+	}
+
+	if len(statuses) != 1 || statuses[0].Applied {
+		t.Fatalf("expected failed migration to be pending")
+	}
+}
+
+func TestMigratorModified(t *testing.T) {
+	workingDir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", path.Join(workingDir, "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	writeMigration(t, workingDir, "1_init", "CREATE TABLE User (id INTEGER PRIMARY KEY);\n", "DROP TABLE User;\n")
+
+	migrator, _ := migration.NewMigrator(db, "sqlite3", workingDir)
+
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("unable to migrate up: %s", err.Error())
+	}
+
+	writeMigration(t, workingDir, "1_init", "CREATE TABLE User (id INTEGER PRIMARY KEY, name TEXT);\n", "DROP TABLE User;\n")
+	writeMigration(t, workingDir, "2_post", "CREATE TABLE Post (id INTEGER PRIMARY KEY);\n", "DROP TABLE Post;\n")
+
+	_, err = migrator.Up()
+	if err == nil || err.Error() != "applied migration 1_init was modified" {
+		t.Fatalf("expected modified migration to be refused, got %v", err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unable to read status: %s", err.Error())
+	}
+
+	if len(statuses) != 2 || !statuses[0].Modified || statuses[1].Applied {
+		t.Fatalf("expected 1_init to be modified and 2_post to be pending")
+	}
+
+	os.Remove(path.Join(workingDir, "1_init.up.sql"))
+
+	_, err = migrator.Down()
+	if err == nil || err.Error() != "applied migration 1_init is missing" {
+		t.Fatalf("expected missing migration to be refused, got %v", err)
+	}
+}
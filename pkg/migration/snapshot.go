@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+)
+
+// SnapshotVersion is bumped whenever the serialized ast changes in a way
+// older snapshots can not be read.
+const SnapshotVersion = 1
+
+// SnapshotFile is the snapshot of the last generated schema, relative to the
+// working directory.
+var SnapshotFile = path.Join("migrations", "snapshot.json")
+
+type snapshot struct {
+	Version int      `json:"version"`
+	Schema  *ast.Ast `json:"schema"`
+}
+
+// LoadSnapshot reads the schema the last migration was generated from. A
+// missing snapshot is an empty schema.
+func LoadSnapshot(file string) (*ast.Ast, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ast.NewAst(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := snapshot{}
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", file, err)
+	}
+
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot %s has version %d, expected %d", file, s.Version, SnapshotVersion)
+	}
+
+	if s.Schema == nil {
+		return ast.NewAst(), nil
+	}
+
+	return s.Schema, nil
+}
+
+// SaveSnapshot writes the schema the next migration is diffed against.
+func SaveSnapshot(file string, schema *ast.Ast) error {
+	data, err := json.MarshalIndent(snapshot{Version: SnapshotVersion, Schema: schema}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(path.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
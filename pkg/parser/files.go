@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/lexer"
+)
+
+const (
+	CodeImportCycle    = "P004"
+	CodeImportNotFound = "P005"
+)
+
+// Extension is the extension of gophoria files, a directory is loaded by
+// loading every file with it.
+const Extension = ".gophoria"
+
+// Loader parses a schema spread over several files. Every file is parsed
+// once, even if it is imported more than once.
+type Loader struct {
+	// ReadFile reads the content of a file, it defaults to os.ReadFile.
+	ReadFile func(name string) ([]byte, error)
+
+	loaded      map[string]struct{}
+	stack       []string
+	diagnostics diagnostic.Diagnostics
+}
+
+func NewLoader() *Loader {
+	l := Loader{
+		ReadFile: os.ReadFile,
+	}
+
+	return &l
+}
+
+// ParseFile parses a single file without following its imports.
+func ParseFile(name string, content []byte) (*ast.Ast, error) {
+	p := NewParser(lexer.NewFileLexer(name, string(content)))
+	return p.Parse()
+}
+
+// Load parses the file, or every gophoria file of the directory, and the
+// files they import into a single ast. Like the parser it carries on after
+// an error, the returned error is a diagnostic.Diagnostics if any file has
+// errors.
+func (l *Loader) Load(name string) (*ast.Ast, error) {
+	l.loaded = map[string]struct{}{}
+	l.stack = []string{}
+	l.diagnostics = diagnostic.Diagnostics{}
+
+	schema := ast.NewAst()
+
+	info, err := os.Stat(name)
+	if err == nil && info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(name, "*"+Extension))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			err = l.load(schema, file, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		err = l.load(schema, name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, l.diagnostics.Err()
+}
+
+// load parses the file into the schema and follows its imports. An error
+// reading an imported file is reported at the import, only an error reading
+// the file passed to Load is returned.
+func (l *Loader) load(schema *ast.Ast, name string, imp *ast.Import) error {
+	key, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+
+	for i, file := range l.stack {
+		if file == key {
+			cycle := []string{}
+			for _, file := range append(l.stack[i:], key) {
+				cycle = append(cycle, filepath.Base(file))
+			}
+
+			diag := diagnostic.Errorf(CodeImportCycle, imp.Path.Token, "import cycle %s", strings.Join(cycle, " -> "))
+			l.diagnostics = append(l.diagnostics, diag)
+			return nil
+		}
+	}
+
+	if _, ok := l.loaded[key]; ok {
+		return nil
+	}
+	l.loaded[key] = struct{}{}
+
+	content, err := l.ReadFile(name)
+	if err != nil {
+		if imp == nil {
+			return err
+		}
+
+		diag := diagnostic.Errorf(CodeImportNotFound, imp.Path.Token, "unable to import %s", imp.Path.Value)
+		l.diagnostics = append(l.diagnostics, diag.WithHint(err.Error()))
+		return nil
+	}
+
+	file, err := ParseFile(name, content)
+
+	var diags diagnostic.Diagnostics
+	if errors.As(err, &diags) {
+		diags.SetFile(name)
+		l.diagnostics = append(l.diagnostics, diags...)
+	}
+
+	schema.Merge(file)
+
+	l.stack = append(l.stack, key)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+	}()
+
+	for _, imported := range file.Imports {
+		err = l.load(schema, filepath.Join(filepath.Dir(name), imported.Path.Value), imported)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
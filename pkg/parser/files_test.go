@@ -0,0 +1,79 @@
+package parser_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/diagnostic"
+	"github.com/gophoria/gophoria/pkg/parser"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(path.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("unable to write %s: %s", name, err.Error())
+		}
+	}
+
+	return dir
+}
+
+func TestLoader(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"project.gophoria": "import \"users.gophoria\"\nimport \"posts.gophoria\"\n\nmodel Tag {\n  id int @id\n}\n",
+		"users.gophoria":   "import \"posts.gophoria\"\n\nmodel User {\n  id int @id\n}\n",
+		"posts.gophoria":   "model Post {\n  id int @id\n}\n",
+	})
+
+	ast, err := parser.NewLoader().Load(path.Join(dir, "project.gophoria"))
+	if err != nil {
+		t.Fatalf("unable to load schema: %s", err.Error())
+	}
+
+	expected := []string{"Tag", "User", "Post"}
+	if len(ast.Models) != len(expected) {
+		t.Fatalf("expected models %v, got %d models", expected, len(ast.Models))
+	}
+
+	for i, name := range expected {
+		if ast.Models[i].Name.Identifier != name {
+			t.Fatalf("expected models %v, got %s at %d", expected, ast.Models[i].Name.Identifier, i)
+		}
+	}
+
+	if ast.Models[2].Name.Token.File != path.Join(dir, "posts.gophoria") {
+		t.Fatalf("expected Post to be read from posts.gophoria, got %s", ast.Models[2].Name.Token.File)
+	}
+
+	ast, err = parser.NewLoader().Load(dir)
+	if err != nil || len(ast.Models) != 3 {
+		t.Fatalf("expected directory to load 3 models, got %v", err)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"project.gophoria": "import \"users.gophoria\"\nimport \"missing.gophoria\"\n",
+		"users.gophoria":   "import \"project.gophoria\"\n",
+	})
+
+	_, err := parser.NewLoader().Load(path.Join(dir, "project.gophoria"))
+
+	diags, ok := err.(diagnostic.Diagnostics)
+	if !ok || len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", err)
+	}
+
+	cycle := diags[0]
+	if cycle.Code != parser.CodeImportCycle || cycle.File != path.Join(dir, "users.gophoria") || cycle.Message != "import cycle project.gophoria -> users.gophoria -> project.gophoria" {
+		t.Fatalf("unexpected diagnostic %s", cycle.Error())
+	}
+
+	missing := diags[1]
+	if missing.Code != parser.CodeImportNotFound || missing.File != path.Join(dir, "project.gophoria") || missing.Start.Row != 1 {
+		t.Fatalf("unexpected diagnostic %s", missing.Error())
+	}
+}
@@ -4,9 +4,16 @@ import (
 	"fmt"
 
 	"github.com/gophoria/gophoria/pkg/ast"
+	"github.com/gophoria/gophoria/pkg/diagnostic"
 	"github.com/gophoria/gophoria/pkg/lexer"
 )
 
+const (
+	CodeUnexpectedToken = "P001"
+	CodeUnclosedBlock   = "P002"
+	CodeIllegalToken    = "P003"
+)
+
 var VariableTypeToken = map[lexer.TokenType]struct{}{
 	lexer.TokenTypeTInt:      {},
 	lexer.TokenTypeTReal:     {},
@@ -19,16 +26,51 @@ var VariableTypeToken = map[lexer.TokenType]struct{}{
 var ArgumentTypeToken = map[lexer.TokenType]struct{}{
 	lexer.TokenTypeString: {},
 	lexer.TokenTypeInt:    {},
+	lexer.TokenTypeReal:   {},
 	lexer.TokenTypeTrue:   {},
 	lexer.TokenTypeFalse:  {},
+	lexer.TokenTypeNull:   {},
 	lexer.TokenTypeIdent:  {},
 }
 
+// LiteralTypeToken are the values of config and enum items, which can not
+// refer to anything by name.
+var LiteralTypeToken = map[lexer.TokenType]struct{}{
+	lexer.TokenTypeString: {},
+	lexer.TokenTypeInt:    {},
+	lexer.TokenTypeReal:   {},
+	lexer.TokenTypeTrue:   {},
+	lexer.TokenTypeFalse:  {},
+	lexer.TokenTypeNull:   {},
+}
+
+var TopLevelToken = map[lexer.TokenType]struct{}{
+	lexer.TokenTypeImport: {},
+	lexer.TokenTypeDb:     {},
+	lexer.TokenTypeUi:     {},
+	lexer.TokenTypeEnum:   {},
+	lexer.TokenTypeModel:  {},
+}
+
 type Parser struct {
 	lexer *lexer.Lexer
 
+	prevToken *lexer.Token
 	currToken *lexer.Token
 	peekToken *lexer.Token
+
+	currDoc []*ast.Comment
+	peekDoc []*ast.Comment
+
+	// currComments are the comments before the current token, the doc
+	// comments among them are in currDoc. A comment on the same line as the
+	// last token of an item is kept apart as the trailing comment of the item.
+	currComments []*ast.Comment
+	peekComments []*ast.Comment
+	currTrailing *ast.Comment
+	prevTrailing *ast.Comment
+
+	diagnostics diagnostic.Diagnostics
 }
 
 func NewParser(lexer *lexer.Lexer) *Parser {
@@ -44,42 +86,106 @@ func NewParser(lexer *lexer.Lexer) *Parser {
 	return &p
 }
 
+// Parse parses the whole input. A syntax error does not stop the parser, it
+// skips to the next item or block and carries on, so the returned error is a
+// diagnostic.Diagnostics holding every error in the input. The returned ast
+// contains everything that could be parsed, even if there were errors.
 func (p *Parser) Parse() (*ast.Ast, error) {
 	ast := ast.NewAst()
 
 	for !p.curTokenIs(lexer.TokenTypeEof) {
-		if p.curTokenIs(lexer.TokenTypeDb) || p.curTokenIs(lexer.TokenTypeUi) {
+		start := p.currToken
+
+		switch p.currToken.Type {
+		case lexer.TokenTypeImport:
+			imp, err := p.parseImport()
+			if err != nil {
+				p.report(err)
+				p.synchronize(start)
+				continue
+			}
+
+			ast.Imports = append(ast.Imports, imp)
+		case lexer.TokenTypeDb, lexer.TokenTypeUi:
 			cfg, err := p.parseConfig()
 			if err != nil {
-				return nil, err
+				p.report(err)
+				p.synchronize(start)
+				continue
 			}
 
 			ast.Config = append(ast.Config, cfg)
-		} else if p.curTokenIs(lexer.TokenTypeEnum) {
+		case lexer.TokenTypeEnum:
 			en, err := p.parseEnum()
 			if err != nil {
-				return nil, err
+				p.report(err)
+				p.synchronize(start)
+				continue
 			}
 
 			ast.Enums = append(ast.Enums, en)
-		} else if p.curTokenIs(lexer.TokenTypeModel) {
-			en, err := p.parseModel()
+		case lexer.TokenTypeModel:
+			model, err := p.parseModel()
 			if err != nil {
-				return nil, err
+				p.report(err)
+				p.synchronize(start)
+				continue
 			}
 
-			ast.Models = append(ast.Models, en)
+			ast.Models = append(ast.Models, model)
+		default:
+			p.report(p.unexpected(p.currToken, "import, model, enum, db or ui"))
+			p.synchronize(start)
 		}
-
-		p.nextToken()
 	}
 
-	return ast, nil
+	ast.Comments = p.currComments
+
+	return ast, p.diagnostics.Err()
+}
+
+// Diagnostics returns every diagnostic reported by the last call to Parse.
+func (p *Parser) Diagnostics() diagnostic.Diagnostics {
+	return p.diagnostics
 }
 
 func (p *Parser) nextToken() {
+	p.prevTrailing = p.currTrailing
+	p.currTrailing = nil
+
+	p.prevToken = p.currToken
 	p.currToken = p.peekToken
+	p.currDoc = p.peekDoc
+	p.currComments = p.peekComments
+	p.peekDoc = nil
+	p.peekComments = nil
+
 	p.peekToken = p.lexer.Next()
+	for p.peekTokenIs(lexer.TokenTypeComment) || p.peekTokenIs(lexer.TokenTypeDocComment) {
+		comment := ast.NewComment(p.peekToken)
+
+		if p.isTrailing(p.peekToken) {
+			p.currTrailing = comment
+		} else {
+			p.peekComments = append(p.peekComments, comment)
+			if p.peekTokenIs(lexer.TokenTypeDocComment) {
+				p.peekDoc = append(p.peekDoc, comment)
+			}
+		}
+
+		p.peekToken = p.lexer.Next()
+	}
+}
+
+// isTrailing reports whether the comment is on the line of the current
+// token. A comment after { or } is printed on its own line, so it is not
+// trailing.
+func (p *Parser) isTrailing(comment *lexer.Token) bool {
+	if p.currToken == nil || len(p.peekComments) > 0 || comment.Row != p.currToken.Row {
+		return false
+	}
+
+	return !p.curTokenIs(lexer.TokenTypeLBrace) && !p.curTokenIs(lexer.TokenTypeRBrace)
 }
 
 func (p *Parser) curTokenIs(tokenType lexer.TokenType) bool {
@@ -90,115 +196,246 @@ func (p *Parser) peekTokenIs(tokenType lexer.TokenType) bool {
 	return p.peekToken.Type == tokenType
 }
 
-func (p *Parser) parseConfig() (*ast.Config, error) {
+// report adds the diagnostic unless the last one is about the same token, a
+// token kept by skipItem may be rejected again by the next item.
+func (p *Parser) report(diag *diagnostic.Diagnostic) {
+	if len(p.diagnostics) > 0 && p.diagnostics[len(p.diagnostics)-1].Start == diag.Start {
+		return
+	}
+
+	p.diagnostics = append(p.diagnostics, diag)
+}
+
+func (p *Parser) unexpected(token *lexer.Token, expected string) *diagnostic.Diagnostic {
+	if token.Type == lexer.TokenTypeIllegal {
+		return diagnostic.Errorf(CodeIllegalToken, token, "illegal character %q", token.Literal)
+	}
+	if token.Type == lexer.TokenTypeError {
+		return diagnostic.Errorf(CodeIllegalToken, token, "%s", token.Literal)
+	}
+
+	return diagnostic.Errorf(CodeUnexpectedToken, token, "expected %s but found %s", expected, describe(token))
+}
+
+// synchronize skips tokens until the start of the next top level block. A
+// closing } is consumed as it ends the block the error happened in. The
+// block started at start is skipped even if the error was reported before
+// reading past its keyword.
+func (p *Parser) synchronize(start *lexer.Token) {
+	if p.currToken == start {
+		p.nextToken()
+	}
+
+	for !p.curTokenIs(lexer.TokenTypeEof) && !p.isTopLevel(p.currToken) {
+		if p.curTokenIs(lexer.TokenTypeRBrace) {
+			p.nextToken()
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
+// skipItem skips the rest of the broken block item started at start, so the
+// parser can continue with the next item of the same block. An unexpected
+// token starting a line after the first line of the item is kept, as it most
+// likely starts the next item.
+func (p *Parser) skipItem(start *lexer.Token, diag *diagnostic.Diagnostic) {
+	for p.currToken.Row <= diag.Start.Row && !p.isBlockEnd() {
+		if p.currToken.Row > start.Row && p.currToken.Row > p.prevToken.Row {
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
+// isBlockEnd reports whether the current token ends a block body, either
+// properly with } or because the block was never closed.
+func (p *Parser) isBlockEnd() bool {
+	return p.curTokenIs(lexer.TokenTypeRBrace) || p.curTokenIs(lexer.TokenTypeEof) || p.isTopLevel(p.currToken)
+}
+
+// closeBlock consumes the } ending the block opened by token and returns
+// the comments before it.
+func (p *Parser) closeBlock(token *lexer.Token) []*ast.Comment {
+	if !p.curTokenIs(lexer.TokenTypeRBrace) {
+		diag := diagnostic.Errorf(CodeUnclosedBlock, token, "%s block is not closed", token.Literal)
+		p.report(diag.WithHint(fmt.Sprintf("expected } but found %s", describe(p.currToken))))
+		return nil
+	}
+
+	comments := p.currComments
+	p.nextToken()
+
+	return comments
+}
+
+func (p *Parser) parseImport() (*ast.Import, *diagnostic.Diagnostic) {
+	if !p.peekTokenIs(lexer.TokenTypeString) {
+		return nil, p.unexpected(p.peekToken, "file name")
+	}
+
+	imp := ast.NewImport(p.currToken, ast.NewValue(p.peekToken))
+	imp.Comments = p.currComments
+
+	p.nextToken()
+	p.nextToken()
+	imp.Trailing = p.prevTrailing
+
+	return imp, nil
+}
+
+func (p *Parser) parseConfig() (*ast.Config, *diagnostic.Diagnostic) {
 	config := ast.NewConfig(p.currToken)
+	config.Comments = p.currComments
 
 	p.nextToken()
 	if !p.curTokenIs(lexer.TokenTypeLBrace) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected { but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
+		return nil, p.unexpected(p.currToken, "{")
 	}
 
 	p.nextToken()
 
-	for !p.curTokenIs(lexer.TokenTypeRBrace) {
+	for !p.isBlockEnd() {
+		start := p.currToken
 		item, err := p.parseAssignItem()
 		if err != nil {
-			return nil, err
+			p.report(err)
+			p.skipItem(start, err)
+			continue
 		}
 
 		config.AddItem(item)
 	}
 
+	config.EndComments = p.closeBlock(config.Token)
+
 	return config, nil
 }
 
-func (p *Parser) parseEnum() (*ast.Enum, error) {
+func (p *Parser) parseEnum() (*ast.Enum, *diagnostic.Diagnostic) {
 	if !p.peekTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
+		return nil, p.unexpected(p.peekToken, "identifier")
 	}
 
 	ident := ast.NewIdentifier(p.peekToken)
 	enum := ast.NewEnum(p.currToken, ident)
+	enum.Doc = p.currDoc
+	enum.Comments = p.currComments
 
 	p.nextToken()
 	p.nextToken()
 
 	if !p.curTokenIs(lexer.TokenTypeLBrace) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected { but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
+		return nil, p.unexpected(p.currToken, "{")
 	}
 	p.nextToken()
 
-	for !p.curTokenIs(lexer.TokenTypeRBrace) {
+	for !p.isBlockEnd() {
+		start := p.currToken
 		item, err := p.parseAssignItem()
 		if err != nil {
-			return nil, err
+			p.report(err)
+			p.skipItem(start, err)
+			continue
 		}
 
 		enum.AddItem(item)
 	}
 
+	enum.EndComments = p.closeBlock(enum.Token)
+
 	return enum, nil
 }
 
-func (p *Parser) parseAssignItem() (*ast.AssignItem, error) {
+func (p *Parser) parseAssignItem() (*ast.AssignItem, *diagnostic.Diagnostic) {
 	if !p.curTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "identifier")
 	}
 	ident := ast.NewIdentifier(p.currToken)
+	doc := p.currDoc
+	comments := p.currComments
 
 	p.nextToken()
 
 	if !p.curTokenIs(lexer.TokenTypeAssign) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected = but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "=")
 	}
-	if !p.peekTokenIs(lexer.TokenTypeString) && !p.peekTokenIs(lexer.TokenTypeInt) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected value but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
-	}
-
-	val := ast.NewValue(p.peekToken)
+	token := p.currToken
+	p.nextToken()
 
-	item := ast.NewAssignItem(p.currToken, ident, val)
+	val, err := p.parseValue(LiteralTypeToken)
+	if err != nil {
+		return nil, err
+	}
 
-	p.nextToken()
-	p.nextToken()
+	item := ast.NewAssignItem(token, ident, val)
+	item.Doc = doc
+	item.Comments = comments
+	item.Trailing = p.prevTrailing
 
 	return item, nil
 }
 
-func (p *Parser) parseModel() (*ast.Model, error) {
+func (p *Parser) parseModel() (*ast.Model, *diagnostic.Diagnostic) {
 	if !p.peekTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
+		return nil, p.unexpected(p.peekToken, "identifier")
 	}
 
 	ident := ast.NewIdentifier(p.peekToken)
 	model := ast.NewModel(p.currToken, ident)
+	model.Doc = p.currDoc
+	model.Comments = p.currComments
 
 	p.nextToken()
 	p.nextToken()
 
 	if !p.curTokenIs(lexer.TokenTypeLBrace) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected { but found %s", p.peekToken.Row, p.peekToken.Col, p.peekToken.Literal)
+		return nil, p.unexpected(p.currToken, "{")
 	}
 	p.nextToken()
 
-	for !p.curTokenIs(lexer.TokenTypeRBrace) {
+	for !p.isBlockEnd() {
+		start := p.currToken
+		if p.curTokenIs(lexer.TokenTypeModelDecorator) {
+			comments := p.currComments
+			dec, err := p.parseDecorator()
+			if err != nil {
+				p.report(err)
+				p.skipItem(start, err)
+				continue
+			}
+			dec.Comments = comments
+			dec.Trailing = p.prevTrailing
+
+			model.Decorators = append(model.Decorators, dec)
+			continue
+		}
+
 		item, err := p.parseDeclaration()
 		if err != nil {
-			return nil, err
+			p.report(err)
+			p.skipItem(start, err)
+			continue
 		}
 
 		model.AddItem(item)
 	}
 
+	model.EndComments = p.closeBlock(model.Token)
+
 	return model, nil
 }
 
-func (p *Parser) parseDeclaration() (*ast.Declaration, error) {
+func (p *Parser) parseDeclaration() (*ast.Declaration, *diagnostic.Diagnostic) {
 	if !p.curTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "identifier")
 	}
 
 	ident := ast.NewIdentifier(p.currToken)
+	doc := p.currDoc
+	comments := p.currComments
 	p.nextToken()
 
 	declType, err := p.parseType()
@@ -207,6 +444,8 @@ func (p *Parser) parseDeclaration() (*ast.Declaration, error) {
 	}
 
 	decl := ast.NewDeclaration(ident, declType)
+	decl.Doc = doc
+	decl.Comments = comments
 
 	for p.curTokenIs(lexer.TokenTypeDecorator) {
 		dec, err := p.parseDecorator()
@@ -216,20 +455,21 @@ func (p *Parser) parseDeclaration() (*ast.Declaration, error) {
 
 		decl.Decorators = append(decl.Decorators, dec)
 	}
+	decl.Trailing = p.prevTrailing
 
 	return decl, nil
 }
 
-func (p *Parser) parseDecorator() (*ast.Decorator, error) {
-	if !p.curTokenIs(lexer.TokenTypeDecorator) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected @ but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+func (p *Parser) parseDecorator() (*ast.Decorator, *diagnostic.Diagnostic) {
+	if !p.curTokenIs(lexer.TokenTypeDecorator) && !p.curTokenIs(lexer.TokenTypeModelDecorator) {
+		return nil, p.unexpected(p.currToken, "@")
 	}
 
 	decToken := p.currToken
 	p.nextToken()
 
 	if !p.curTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "decorator name")
 	}
 
 	name := ast.NewIdentifier(p.currToken)
@@ -249,12 +489,12 @@ func (p *Parser) parseDecorator() (*ast.Decorator, error) {
 	return dec, nil
 }
 
-func (p *Parser) parseCallable() (*ast.Callable, error) {
+func (p *Parser) parseCallable() (*ast.Callable, *diagnostic.Diagnostic) {
 	if !p.curTokenIs(lexer.TokenTypeIdent) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "identifier")
 	}
 	if !p.peekTokenIs(lexer.TokenTypeLParen) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected ( but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.peekToken, "(")
 	}
 
 	ident := ast.NewIdentifier(p.currToken)
@@ -272,19 +512,28 @@ func (p *Parser) parseCallable() (*ast.Callable, error) {
 
 		if p.curTokenIs(lexer.TokenTypeComma) {
 			p.nextToken()
+		} else if !p.curTokenIs(lexer.TokenTypeRParen) {
+			return nil, p.unexpected(p.currToken, ", or )")
 		}
 	}
-	if !p.curTokenIs(lexer.TokenTypeRParen) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected ) but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
-	}
+	callable.End = p.currToken
 	p.nextToken()
 
 	return callable, nil
 }
 
-func (p *Parser) parseArgument() (*ast.Argument, error) {
+func (p *Parser) parseArgument() (*ast.Argument, *diagnostic.Diagnostic) {
+	if p.curTokenIs(lexer.TokenTypeLSquareBrace) {
+		value, err := p.parseValue(ArgumentTypeToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewArgument(nil, value, nil), nil
+	}
+
 	if !p.isValidArgument(p.currToken) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected argument but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		return nil, p.unexpected(p.currToken, "argument")
 	}
 
 	if p.peekTokenIs(lexer.TokenTypeLParen) {
@@ -299,26 +548,68 @@ func (p *Parser) parseArgument() (*ast.Argument, error) {
 		name := ast.NewIdentifier(p.currToken)
 		p.nextToken()
 		p.nextToken()
-		if !p.curTokenIs(lexer.TokenTypeIdent) {
-			return nil, fmt.Errorf("[line: %d, col: %d]: expected identifier but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+
+		value, err := p.parseValue(ArgumentTypeToken)
+		if err != nil {
+			return nil, err
 		}
-		value := ast.NewValue(p.currToken)
-		p.nextToken()
 
 		arg := ast.NewArgument(name, value, nil)
 		return arg, nil
 	}
 
-	value := ast.NewValue(p.currToken)
+	value, err := p.parseValue(ArgumentTypeToken)
+	if err != nil {
+		return nil, err
+	}
+
 	arg := ast.NewArgument(nil, value, nil)
+	return arg, nil
+}
+
+// parseValue parses a single value, of one of the valid token types, or a
+// list of values in square brackets.
+func (p *Parser) parseValue(valid map[lexer.TokenType]struct{}) (*ast.Value, *diagnostic.Diagnostic) {
+	if !p.curTokenIs(lexer.TokenTypeLSquareBrace) {
+		if _, ok := valid[p.currToken.Type]; !ok {
+			return nil, p.unexpected(p.currToken, "value")
+		}
+
+		value := ast.NewValue(p.currToken)
+		p.nextToken()
+
+		return value, nil
+	}
+
+	list := ast.NewListValue(p.currToken)
 	p.nextToken()
 
-	return arg, nil
+	for !p.curTokenIs(lexer.TokenTypeRSquareBrace) {
+		item, err := p.parseValue(valid)
+		if err != nil {
+			return nil, err
+		}
+		list.AddItem(item)
+
+		if p.curTokenIs(lexer.TokenTypeComma) {
+			p.nextToken()
+		} else if !p.curTokenIs(lexer.TokenTypeRSquareBrace) {
+			return nil, p.unexpected(p.currToken, ", or ]")
+		}
+	}
+	p.nextToken()
+
+	return list, nil
 }
 
-func (p *Parser) parseType() (*ast.DeclarationType, error) {
+func (p *Parser) parseType() (*ast.DeclarationType, *diagnostic.Diagnostic) {
 	if !p.isValidvariableType(p.currToken) {
-		return nil, fmt.Errorf("[line: %d, col: %d]: expected type but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+		diag := p.unexpected(p.currToken, "type")
+		if diag.Code == CodeUnexpectedToken {
+			diag.WithHint("a type is int, real, bool, string, DateTime or the name of a model or enum")
+		}
+
+		return nil, diag
 	}
 	typeToken := p.currToken
 	isArray := false
@@ -329,7 +620,7 @@ func (p *Parser) parseType() (*ast.DeclarationType, error) {
 
 		p.nextToken()
 		if !p.curTokenIs(lexer.TokenTypeRSquareBrace) {
-			return nil, fmt.Errorf("[line: %d, col: %d]: expected ] but found %s", p.currToken.Row, p.currToken.Col, p.currToken.Literal)
+			return nil, p.unexpected(p.currToken, "]")
 		}
 		p.nextToken()
 	}
@@ -348,3 +639,19 @@ func (p *Parser) isValidArgument(token *lexer.Token) bool {
 	_, ok := ArgumentTypeToken[token.Type]
 	return ok
 }
+
+func (p *Parser) isTopLevel(token *lexer.Token) bool {
+	_, ok := TopLevelToken[token.Type]
+	return ok
+}
+
+func describe(token *lexer.Token) string {
+	switch token.Type {
+	case lexer.TokenTypeEof:
+		return "end of file"
+	case lexer.TokenTypeString:
+		return fmt.Sprintf("%q", token.Literal)
+	}
+
+	return token.Literal
+}
@@ -3,6 +3,7 @@ package parser_test
 import (
 	"testing"
 
+	"github.com/gophoria/gophoria/pkg/ast"
 	"github.com/gophoria/gophoria/pkg/lexer"
 	"github.com/gophoria/gophoria/pkg/parser"
 )
@@ -59,6 +60,49 @@ db {
 	}
 }
 
+func TestParserValues(t *testing.T) {
+	input := `
+db {
+  provider = "postgres"
+  port = 5432
+  ratio = -0.5
+  debug = true
+  password = null
+  schemas = ["public", "audit"]
+}`
+
+	expected := []struct {
+		valueType ast.ValueType
+		value     string
+	}{
+		{ast.ValueTypeString, "\"postgres\""},
+		{ast.ValueTypeInt, "5432"},
+		{ast.ValueTypeReal, "-0.5"},
+		{ast.ValueTypeBool, "true"},
+		{ast.ValueTypeNull, "null"},
+		{ast.ValueTypeList, "[\"public\", \"audit\"]"},
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	schema, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	items := schema.Config[0].Items
+	if len(items) != len(expected) {
+		t.Fatalf("expected %d items in db but found %d", len(expected), len(items))
+	}
+
+	for i, exp := range expected {
+		if items[i].Value.Type != exp.valueType || items[i].Value.String() != exp.value {
+			t.Fatalf("expected value %s of type %d but got %s of type %d", exp.value, exp.valueType, items[i].Value, items[i].Value.Type)
+		}
+	}
+}
+
 func TestEnum(t *testing.T) {
 	input := `
 enum Role {
@@ -312,3 +356,229 @@ model Post {
 		}
 	}
 }
+
+func TestModelDecorators(t *testing.T) {
+	input := `
+model Post {
+  id        int     @id
+  title     string
+  authorId  int
+
+  @@unique([title, authorId])
+  @@unique(fields: [title])
+}`
+
+	expected := []string{
+		"@@unique([title, authorId])",
+		"@@unique(fields: [title])",
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+	}
+
+	model := ast.Models[0]
+
+	if len(model.Items) != 3 {
+		t.Fatalf("expected 3 items in Post but found %d", len(model.Items))
+	}
+
+	if len(model.Decorators) != len(expected) {
+		t.Fatalf("expected %d model decorators but found %d", len(expected), len(model.Decorators))
+	}
+
+	for i, exp := range expected {
+		if model.Decorators[i].String() != exp {
+			t.Fatalf("expected decorator %s but got %s", exp, model.Decorators[i])
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `
+// not attached to anything
+/// Role of a user.
+enum Role {
+  /// Administrator.
+  admin = "admin"
+  user = "user" // trailing
+}
+
+/// User of the system.
+/// Stored in the users table.
+model User {
+  /// Unique identifier.
+  id      string    @id
+  // plain comment
+  name    string
+}`
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("parser error: %s", err.Error())
+		return
+	}
+
+	en := ast.Enums[0]
+	if len(en.Doc) != 1 || en.Doc[0].Text != "Role of a user." {
+		t.Fatalf("unexpected enum doc %v", en.Doc)
+	}
+
+	if len(en.Items[0].Doc) != 1 || en.Items[0].Doc[0].Text != "Administrator." {
+		t.Fatalf("unexpected enum item doc %v", en.Items[0].Doc)
+	}
+
+	if len(en.Items[1].Doc) != 0 {
+		t.Fatalf("expected no doc for enum item user but found %d", len(en.Items[1].Doc))
+	}
+
+	model := ast.Models[0]
+	if len(model.Doc) != 2 || model.Doc[0].Text != "User of the system." || model.Doc[1].Text != "Stored in the users table." {
+		t.Fatalf("unexpected model doc %v", model.Doc)
+	}
+
+	if len(model.Items) != 2 {
+		t.Fatalf("expected 2 items in User but found %d", len(model.Items))
+	}
+
+	if len(model.Items[0].Doc) != 1 || model.Items[0].Doc[0].Text != "Unique identifier." {
+		t.Fatalf("unexpected declaration doc %v", model.Items[0].Doc)
+	}
+
+	if len(model.Items[1].Doc) != 0 {
+		t.Fatalf("expected no doc for name but found %d", len(model.Items[1].Doc))
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	input := `
+db {
+  provider = "sqlite3"
+  url =
+}
+
+enum Role {
+  admin = "admin"
+  user "user"
+}
+
+enum {
+}
+
+model User {
+  id      string  @id
+  surname %
+  role    Role
+}
+
+model Post {
+  id int
+`
+
+	expected := []struct {
+		code string
+		row  int
+		col  int
+	}{
+		{parser.CodeUnexpectedToken, 4, 0},
+		{parser.CodeUnexpectedToken, 8, 7},
+		{parser.CodeUnexpectedToken, 11, 5},
+		{parser.CodeIllegalToken, 16, 10},
+		{parser.CodeUnclosedBlock, 20, 0},
+	}
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err == nil {
+		t.Fatalf("expected parser error")
+	}
+
+	diags := parser.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d:\n%s", len(expected), len(diags), err.Error())
+	}
+
+	for i, exp := range expected {
+		diag := diags[i]
+		if diag.Code != exp.code || diag.Start.Row != exp.row || diag.Start.Col != exp.col {
+			t.Fatalf("expected %s at %d:%d but got %s", exp.code, exp.row, exp.col, diag.Error())
+		}
+	}
+
+	if len(ast.Models) != 2 {
+		t.Fatalf("expected 2 models but found %d", len(ast.Models))
+	}
+
+	if len(ast.Models[0].Items) != 2 {
+		t.Fatalf("expected 2 items in User but found %d", len(ast.Models[0].Items))
+	}
+}
+
+func TestDiagnosticsMissingValue(t *testing.T) {
+	input := `
+db {
+  provider =
+  lib = "sqlx"
+}`
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err == nil {
+		t.Fatalf("expected parser error")
+	}
+
+	diags := parser.Diagnostics()
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic but found %d:\n%s", len(diags), err.Error())
+	}
+	if diags[0].Start.Row != 3 {
+		t.Fatalf("expected diagnostic on row 3 but got %s", diags[0].Error())
+	}
+
+	if len(ast.Config) != 1 || len(ast.Config[0].Items) != 1 {
+		t.Fatalf("expected the lib item to be parsed")
+	}
+	if ast.Config[0].Items[0].Identifier.Identifier != "lib" {
+		t.Fatalf("expected lib but found %s", ast.Config[0].Items[0].Identifier.Identifier)
+	}
+}
+
+func TestDiagnosticsMissingType(t *testing.T) {
+	input := `
+model User {
+  name
+  42
+  email string
+}`
+
+	lexer := lexer.NewLexer(input)
+	parser := parser.NewParser(lexer)
+
+	ast, err := parser.Parse()
+	if err == nil {
+		t.Fatalf("expected parser error")
+	}
+
+	diags := parser.Diagnostics()
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic but found %d:\n%s", len(diags), err.Error())
+	}
+	if diags[0].Start.Row != 3 {
+		t.Fatalf("expected diagnostic on row 3 but got %s", diags[0].Error())
+	}
+
+	if len(ast.Models) != 1 || len(ast.Models[0].Items) != 1 {
+		t.Fatalf("expected the email item to be parsed")
+	}
+}
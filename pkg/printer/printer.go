@@ -0,0 +1,28 @@
+package printer
+
+import (
+	"io"
+
+	"github.com/gophoria/gophoria/pkg/ast"
+)
+
+// Printer writes an ast in the canonical format of gophoria files: config
+// blocks first, then enums and models, with the names, types and decorators
+// of model fields aligned in columns. Comments are kept with the item they
+// are written before or after.
+type Printer struct {
+	writer io.Writer
+}
+
+func NewPrinter(writer io.Writer) *Printer {
+	p := Printer{
+		writer: writer,
+	}
+
+	return &p
+}
+
+func (p *Printer) Print(schema *ast.Ast) error {
+	_, err := p.writer.Write([]byte(schema.String()))
+	return err
+}
@@ -0,0 +1,140 @@
+package printer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gophoria/gophoria/pkg/lexer"
+	"github.com/gophoria/gophoria/pkg/parser"
+	"github.com/gophoria/gophoria/pkg/printer"
+)
+
+func printSchema(t *testing.T, input string) string {
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+
+	ast, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unable to parse input: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	err = printer.NewPrinter(&buf).Print(ast)
+	if err != nil {
+		t.Fatalf("unable to print ast: %s", err.Error())
+	}
+
+	return buf.String()
+}
+
+func TestPrinter(t *testing.T) {
+	input := `
+model Post {
+  id int @id @default(autoincrement())
+    /// shown in lists
+  title string @unique
+  author User @relation(field: authorId, reference: id, onDelete: Cascade)
+  authorId int
+  tags Tag[]
+  @@unique([title, authorId])
+}
+db {
+provider = "sqlite3"
+  url = ":memory:"
+}
+
+/// role of a user
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+`
+
+	expected := `db {
+  provider = "sqlite3"
+  url = ":memory:"
+}
+
+/// role of a user
+enum Role {
+  admin = "admin"
+  user = "user"
+}
+
+model Post {
+  id       int    @id @default(autoincrement())
+  /// shown in lists
+  title    string @unique
+  author   User   @relation(field: authorId, reference: id, onDelete: Cascade)
+  authorId int
+  tags     Tag[]
+
+  @@unique([title, authorId])
+}
+`
+
+	actual := printSchema(t, input)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	again := printSchema(t, actual)
+	if again != actual {
+		t.Fatalf("expected printing to be stable, got:\n%s", again)
+	}
+}
+
+func TestPrinterComments(t *testing.T) {
+	input := `// blog schema
+
+db {
+provider = "sqlite3" // driver
+  // more to come
+}
+
+model Post { // posts
+  id int @id @default(autoincrement()) // key
+  title string @unique
+
+
+  authorId int // author
+  // keys
+  @@unique([title, authorId])
+  // end
+}
+
+// end of file
+`
+
+	expected := `// blog schema
+
+db {
+  provider = "sqlite3" // driver
+  // more to come
+}
+
+model Post {
+  // posts
+  id    int    @id @default(autoincrement()) // key
+  title string @unique
+
+  authorId int // author
+
+  // keys
+  @@unique([title, authorId])
+  // end
+}
+
+// end of file
+`
+
+	actual := printSchema(t, input)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	again := printSchema(t, actual)
+	if again != actual {
+		t.Fatalf("expected printing to be stable, got:\n%s", again)
+	}
+}